@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const apiResourcePrefix = "specmill://api"
+
+// ErrInvalidResourceArguments is returned when the values extracted from a
+// resource URI do not satisfy the operation's parameter schemas.
+var ErrInvalidResourceArguments = errors.New("invalid resource arguments")
+
+var pathVariable = regexp.MustCompile(`\{([^}]+)\}`)
+
+// apiResource exposes a GET operation as a resource template whose URIs are
+// built from the operation's path.
+type apiResource struct {
+	ref         *operationRef
+	path        string
+	uriTemplate string
+	pattern     *regexp.Regexp
+	variables   []string
+	query       []string
+}
+
+func newAPIResource(ref *operationRef) *apiResource {
+	path := ref.path
+	for name, value := range ref.pinned {
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(formatArgument(value)))
+	}
+
+	r := &apiResource{ref: ref, path: path}
+
+	pattern := "^" + regexp.QuoteMeta(apiResourcePrefix)
+	last := 0
+	for _, loc := range pathVariable.FindAllStringSubmatchIndex(path, -1) {
+		pattern += regexp.QuoteMeta(path[last:loc[0]]) + "([^/?]+)"
+		r.variables = append(r.variables, path[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	pattern += regexp.QuoteMeta(path[last:]) + "$"
+	r.pattern = regexp.MustCompile(pattern)
+
+	for _, param := range ref.operation.Parameters {
+		if _, ok := ref.pinned[param.Name]; ok || param.In != "query" {
+			continue
+		}
+		r.query = append(r.query, param.Name)
+	}
+
+	r.uriTemplate = apiResourcePrefix + path
+	if len(r.query) > 0 {
+		r.uriTemplate += "{?" + strings.Join(r.query, ",") + "}"
+	}
+	return r
+}
+
+// concreteURI returns the URI of a resource that takes no path variables
+// and no required query parameters, or "" if the template needs values.
+func (r *apiResource) concreteURI() string {
+	if len(r.variables) > 0 {
+		return ""
+	}
+	for _, param := range r.ref.operation.Parameters {
+		if param.In == "query" && param.Required {
+			if _, ok := r.ref.pinned[param.Name]; !ok {
+				return ""
+			}
+		}
+	}
+	return apiResourcePrefix + r.path
+}
+
+func (r *apiResource) template() MCPResourceTemplate {
+	op := r.ref.operation
+	description := op.Summary
+	if description == "" {
+		description = fmt.Sprintf("GET %s", r.ref.path)
+	}
+
+	template := MCPResourceTemplate{
+		URITemplate: r.uriTemplate,
+		Name:        op.OperationID,
+		Description: description,
+	}
+	if _, _, ok := successJSONResponse(op.Responses); ok {
+		template.MimeType = "application/json"
+	}
+	return template
+}
+
+// match extracts the arguments encoded in uri, reporting false if the URI
+// does not belong to this resource.
+func (r *apiResource) match(uri string) (map[string]interface{}, bool) {
+	path, rawQuery, _ := strings.Cut(uri, "?")
+	m := r.pattern.FindStringSubmatch(path)
+	if m == nil {
+		return nil, false
+	}
+
+	args := make(map[string]interface{})
+	for i, name := range r.variables {
+		value, err := url.PathUnescape(m[i+1])
+		if err != nil {
+			return nil, false
+		}
+		args[name] = value
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, false
+	}
+	for _, name := range r.query {
+		if values, ok := query[name]; ok && len(values) > 0 {
+			args[name] = values[0]
+		}
+	}
+	return args, true
+}
+
+// findAPIResource returns the resource matching uri and the arguments it
+// encodes. When several templates match, the one with the fewest path
+// variables wins so that literal paths like /pets/findByStatus take
+// precedence over /pets/{petId}.
+func (g *MCPGenerator) findAPIResource(uri string) (*apiResource, map[string]interface{}) {
+	var resource *apiResource
+	var args map[string]interface{}
+	for _, r := range g.apiResources {
+		if matched, ok := r.match(uri); ok && (resource == nil || len(r.variables) < len(resource.variables)) {
+			resource, args = r, matched
+		}
+	}
+	return resource, args
+}
+
+// fetchAPIResource performs the upstream GET for the resource matching uri,
+// sending etag as If-None-Match when it is set. A 304 response is returned
+// as is; 404 and other error statuses are returned as errors.
+func (g *MCPGenerator) fetchAPIResource(ctx context.Context, uri, etag string) (*http.Response, []byte, error) {
+	resource, args := g.findAPIResource(uri)
+	if resource == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+
+	// Values taken from a URI are strings, so they are always coerced.
+	if _, errs := g.prepareArguments(resource.ref, args, true); len(errs) > 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidResourceArguments, formatValidationErrors(errs))
+	}
+
+	req, err := g.newRequest(ctx, resource.ref, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, data, err := g.send(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, nil, fmt.Errorf("upstream returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	return resp, data, nil
+}
+
+func (g *MCPGenerator) readAPIResource(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	resp, data, err := g.fetchAPIResource(ctx, uri, "")
+	if err != nil {
+		return nil, err
+	}
+
+	mimeType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		mimeType = "text/plain"
+	}
+
+	return &ReadResourceResult{
+		Contents: []ResourceContents{{URI: uri, MimeType: mimeType, Text: string(data)}},
+	}, nil
+}
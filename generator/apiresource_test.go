@@ -0,0 +1,182 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"specmill/parser"
+)
+
+func apiResourceTestSpec(serverURL string) *parser.OpenAPISpec {
+	jsonResponse := map[string]parser.Response{
+		"200": {
+			Description: "OK",
+			Content: map[string]parser.MediaType{
+				"application/json": {Schema: &parser.Schema{Type: "object"}},
+			},
+		},
+	}
+
+	return &parser.OpenAPISpec{
+		Servers: []parser.Server{{URL: serverURL}},
+		Paths: map[string]parser.PathItem{
+			"/pets/{petId}": {
+				Get: &parser.Operation{
+					OperationID: "getPet",
+					Summary:     "Get a pet",
+					Parameters: []parser.Parameter{
+						{Name: "petId", In: "path", Required: true, Schema: &parser.Schema{Type: "integer"}},
+						{Name: "fields", In: "query", Schema: &parser.Schema{Type: "string"}},
+					},
+					Responses: jsonResponse,
+				},
+				Delete: &parser.Operation{
+					OperationID: "deletePet",
+					Parameters: []parser.Parameter{
+						{Name: "petId", In: "path", Required: true, Schema: &parser.Schema{Type: "integer"}},
+					},
+				},
+			},
+			"/pets/findByStatus": {
+				Get: &parser.Operation{
+					OperationID: "findPetsByStatus",
+					Parameters: []parser.Parameter{
+						{Name: "status", In: "query", Required: true, Schema: &parser.Schema{Type: "string"}},
+					},
+					Responses: jsonResponse,
+				},
+			},
+			"/files/{name}": {
+				Get: &parser.Operation{
+					OperationID: "getFile",
+					Parameters: []parser.Parameter{
+						{Name: "name", In: "path", Required: true, Schema: &parser.Schema{Type: "string"}},
+					},
+					Responses: jsonResponse,
+				},
+			},
+			"/store/inventory": {
+				Get: &parser.Operation{
+					OperationID: "getInventory",
+					Responses:   jsonResponse,
+				},
+			},
+		},
+	}
+}
+
+func TestAPIResourceTemplates(t *testing.T) {
+	cfg := &Config{Operations: map[string]OperationConfig{
+		"getPet":           {Resource: true},
+		"findPetsByStatus": {Resource: true},
+		"getInventory":     {Resource: true},
+	}}
+	gen := NewMCPGeneratorWithConfig(apiResourceTestSpec("https://api.example.com"), cfg)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	templates := gen.GetResourceTemplates()[2:]
+	expected := []string{
+		"specmill://api/pets/findByStatus{?status}",
+		"specmill://api/pets/{petId}{?fields}",
+		"specmill://api/store/inventory",
+	}
+	if len(templates) != len(expected) {
+		t.Fatalf("Expected %d API templates, got: %+v", len(expected), templates)
+	}
+	for i, uriTemplate := range expected {
+		if templates[i].URITemplate != uriTemplate {
+			t.Errorf("Expected template %s, got: %s", uriTemplate, templates[i].URITemplate)
+		}
+		if templates[i].MimeType != "application/json" {
+			t.Errorf("Expected JSON mime type for %s, got: %s", uriTemplate, templates[i].MimeType)
+		}
+	}
+
+	found := false
+	for _, resource := range gen.GetResources() {
+		if resource.URI == "specmill://api/store/inventory" {
+			found = true
+		}
+		if strings.HasPrefix(resource.URI, "specmill://api/pets") {
+			t.Errorf("Parameterized operation should not be listed as a resource: %s", resource.URI)
+		}
+	}
+	if !found {
+		t.Error("Expected parameterless GET to be listed as a resource")
+	}
+
+	cfg.Operations["deletePet"] = OperationConfig{Resource: true}
+	if err := gen.GenerateTools(); err == nil {
+		t.Error("Expected error when exposing a DELETE operation as a resource")
+	}
+}
+
+func TestReadAPIResource(t *testing.T) {
+	var requested string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		if r.URL.Path == "/pets/404" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	cfg := &Config{Operations: map[string]OperationConfig{
+		"getPet":           {Resource: true},
+		"findPetsByStatus": {Resource: true},
+		"getFile":          {Resource: true},
+	}}
+	gen := NewMCPGeneratorWithConfig(apiResourceTestSpec(upstream.URL), cfg)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	tests := []struct {
+		uri       string
+		requested string
+		err       error
+	}{
+		{uri: "specmill://api/pets/7", requested: "/pets/7"},
+		{uri: "specmill://api/pets/7?fields=name", requested: "/pets/7?fields=name"},
+		{uri: "specmill://api/pets/5555555", requested: "/pets/5555555"},
+		{uri: "specmill://api/pets/findByStatus?status=sold", requested: "/pets/findByStatus?status=sold"},
+		{uri: "specmill://api/files/..%2Fadmin%2Fsecrets", requested: "/files/..%2Fadmin%2Fsecrets"},
+		{uri: "specmill://api/files/a%2Fb%3Fx=1", requested: "/files/a%2Fb%3Fx=1"},
+		{uri: "specmill://api/pets/findByStatus", err: ErrInvalidResourceArguments},
+		{uri: "specmill://api/pets/rex", err: ErrInvalidResourceArguments},
+		{uri: "specmill://api/pets/404", err: ErrResourceNotFound},
+		{uri: "specmill://api/store/inventory", err: ErrResourceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			requested = ""
+			result, err := gen.ReadResource(context.Background(), tt.uri)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Expected error %v, got: %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to read resource: %v", err)
+			}
+			if requested != tt.requested {
+				t.Errorf("Expected upstream request %s, got: %s", tt.requested, requested)
+			}
+			contents := result.Contents[0]
+			if contents.URI != tt.uri || contents.MimeType != "application/json" || contents.Text != `{"ok":true}` {
+				t.Errorf("Unexpected contents: %+v", contents)
+			}
+		})
+	}
+}
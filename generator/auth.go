@@ -0,0 +1,21 @@
+package generator
+
+import "net/http"
+
+// RequestEditor modifies an upstream request before it is sent, typically to
+// add credentials.
+type RequestEditor func(req *http.Request) error
+
+func BearerAuth(token string) RequestEditor {
+	return func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+func HeaderAuth(name, value string) RequestEditor {
+	return func(req *http.Request) error {
+		req.Header.Set(name, value)
+		return nil
+	}
+}
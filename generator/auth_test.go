@@ -0,0 +1,24 @@
+package generator
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAuthEditors(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/pets", nil)
+
+	if err := BearerAuth("secret")(req); err != nil {
+		t.Fatalf("BearerAuth failed: %v", err)
+	}
+	if req.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Expected bearer token, got: %s", req.Header.Get("Authorization"))
+	}
+
+	if err := HeaderAuth("X-API-Key", "key")(req); err != nil {
+		t.Fatalf("HeaderAuth failed: %v", err)
+	}
+	if req.Header.Get("X-API-Key") != "key" {
+		t.Errorf("Expected API key header, got: %s", req.Header.Get("X-API-Key"))
+	}
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Coercion struct {
+	Path string `json:"path"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// coerceArguments converts loosely typed values produced by models (numbers
+// and booleans sent as strings, objects and arrays sent as JSON-encoded
+// strings) to the types declared in schema. args is modified in place.
+func coerceArguments(schema map[string]interface{}, args map[string]interface{}) []Coercion {
+	_, coercions := coerceValue(schema, args, "$")
+	return coercions
+}
+
+func coerceValue(schema map[string]interface{}, value interface{}, path string) (interface{}, []Coercion) {
+	if schema == nil || value == nil {
+		return value, nil
+	}
+
+	var coercions []Coercion
+
+	schemaType, _ := schema["type"].(string)
+	if schemaType != "" && !matchesType(schema, value) {
+		if converted, ok := convertValue(schemaType, value); ok {
+			coercions = append(coercions, Coercion{
+				Path: path,
+				From: jsonTypeName(value),
+				To:   schemaType,
+			})
+			value = converted
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			propSchema, ok := properties[name].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			converted, nested := coerceValue(propSchema, v[name], childPath(path, name))
+			v[name] = converted
+			coercions = append(coercions, nested...)
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				converted, nested := coerceValue(items, item, indexPath(path, i))
+				v[i] = converted
+				coercions = append(coercions, nested...)
+			}
+		}
+	}
+
+	return value, coercions
+}
+
+func convertValue(schemaType string, value interface{}) (interface{}, bool) {
+	switch schemaType {
+	case "integer":
+		// Integers beyond 2^53 would lose digits as float64, so they stay
+		// strings rather than reaching the upstream API altered.
+		if s, ok := value.(string); ok {
+			if f, ok := parseFinite(s); ok && f == math.Trunc(f) && math.Abs(f) <= 1<<53 {
+				return f, true
+			}
+		}
+	case "number":
+		if s, ok := value.(string); ok {
+			if f, ok := parseFinite(s); ok {
+				return f, true
+			}
+		}
+	case "boolean":
+		if s, ok := value.(string); ok {
+			if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+				return b, true
+			}
+		}
+	case "string":
+		switch v := value.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		case bool:
+			return strconv.FormatBool(v), true
+		}
+	case "object":
+		if s, ok := value.(string); ok {
+			var obj map[string]interface{}
+			if err := json.Unmarshal([]byte(s), &obj); err == nil && obj != nil {
+				return obj, true
+			}
+		}
+	case "array":
+		if s, ok := value.(string); ok {
+			var arr []interface{}
+			if err := json.Unmarshal([]byte(s), &arr); err == nil && arr != nil {
+				return arr, true
+			}
+		}
+	}
+	return value, false
+}
+
+// parseFinite parses a number, rejecting the infinities and NaN that
+// strconv accepts but JSON cannot represent.
+func parseFinite(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, false
+	}
+	return f, true
+}
@@ -0,0 +1,179 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"specmill/parser"
+)
+
+func TestCoerceArguments(t *testing.T) {
+	schema := map[string]interface{}{}
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"limit": {"type": "integer"},
+			"ratio": {"type": "number"},
+			"verbose": {"type": "boolean"},
+			"name": {"type": "string"},
+			"ids": {"type": "array", "items": {"type": "integer"}},
+			"body": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "integer"},
+					"tags": {"type": "array", "items": {"type": "string"}}
+				}
+			}
+		}
+	}`), &schema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		args      string
+		expected  string
+		coercions []Coercion
+	}{
+		{
+			name:     "Already typed",
+			args:     `{"limit": 1, "verbose": true}`,
+			expected: `{"limit": 1, "verbose": true}`,
+		},
+		{
+			name:     "Scalars from strings",
+			args:     `{"limit": "10", "ratio": " 0.5", "verbose": "true", "name": 42}`,
+			expected: `{"limit": 10, "ratio": 0.5, "verbose": true, "name": "42"}`,
+			coercions: []Coercion{
+				{Path: "$.limit", From: "string", To: "integer"},
+				{Path: "$.name", From: "integer", To: "string"},
+				{Path: "$.ratio", From: "string", To: "number"},
+				{Path: "$.verbose", From: "string", To: "boolean"},
+			},
+		},
+		{
+			name:     "Unconvertible values are left alone",
+			args:     `{"limit": "1.5", "verbose": "maybe"}`,
+			expected: `{"limit": "1.5", "verbose": "maybe"}`,
+		},
+		{
+			name:     "Non-finite numbers are left alone",
+			args:     `{"limit": "Inf", "ratio": "NaN", "ids": ["-Infinity"]}`,
+			expected: `{"limit": "Inf", "ratio": "NaN", "ids": ["-Infinity"]}`,
+		},
+		{
+			name:     "Integers beyond float64 precision are left alone",
+			args:     `{"limit": "12345678901234567891"}`,
+			expected: `{"limit": "12345678901234567891"}`,
+		},
+		{
+			name:     "JSON-encoded body and array items",
+			args:     `{"ids": ["1", 2], "body": "{\"id\": \"3\", \"tags\": \"[\\\"a\\\"]\"}"}`,
+			expected: `{"ids": [1, 2], "body": {"id": 3, "tags": ["a"]}}`,
+			coercions: []Coercion{
+				{Path: "$.body", From: "string", To: "object"},
+				{Path: "$.body.id", From: "string", To: "integer"},
+				{Path: "$.body.tags", From: "string", To: "array"},
+				{Path: "$.ids[0]", From: "string", To: "integer"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args, expected map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.args), &args); err != nil {
+				t.Fatalf("Failed to parse args: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.expected), &expected); err != nil {
+				t.Fatalf("Failed to parse expected: %v", err)
+			}
+
+			coercions := coerceArguments(schema, args)
+			if !reflect.DeepEqual(args, expected) {
+				t.Errorf("Expected %v, got: %v", expected, args)
+			}
+			if len(coercions) != len(tt.coercions) {
+				t.Fatalf("Expected %d coercions, got: %v", len(tt.coercions), coercions)
+			}
+			for i := range tt.coercions {
+				if coercions[i] != tt.coercions[i] {
+					t.Errorf("Expected coercion %v, got: %v", tt.coercions[i], coercions[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteToolCoercion(t *testing.T) {
+	var query string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	spec := &parser.OpenAPISpec{
+		Servers: []parser.Server{{URL: upstream.URL}},
+		Paths: map[string]parser.PathItem{
+			"/pets": {
+				Get: &parser.Operation{
+					OperationID: "listPets",
+					Parameters: []parser.Parameter{
+						{Name: "limit", In: "query", Schema: &parser.Schema{Type: "integer"}},
+					},
+				},
+			},
+		},
+	}
+
+	gen := NewMCPGenerator(spec)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	result, err := gen.ExecuteTool(context.Background(), "listPets", json.RawMessage(`{"limit": "5"}`))
+	if err != nil {
+		t.Fatalf("Failed to execute tool: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected validation error when coercion is disabled")
+	}
+
+	gen = NewMCPGeneratorWithConfig(spec, &Config{CoerceArguments: true})
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	result, err = gen.ExecuteTool(context.Background(), "listPets", json.RawMessage(`{"limit": "5"}`))
+	if err != nil {
+		t.Fatalf("Failed to execute tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected coerced call to succeed, got: %s", result.Content[0].Text)
+	}
+	if query != "limit=5" {
+		t.Errorf("Expected query 'limit=5', got: %s", query)
+	}
+
+	coercions, ok := result.Meta["specmill/coercions"].([]Coercion)
+	if !ok || len(coercions) != 1 || coercions[0].Path != "$.limit" {
+		t.Errorf("Expected coercion of $.limit in result metadata, got: %v", result.Meta)
+	}
+
+	result, err = gen.ExecuteTool(context.Background(), "listPets", json.RawMessage(`{"limit": "9876543"}`))
+	if err != nil {
+		t.Fatalf("Failed to execute tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected coerced call to succeed, got: %s", result.Content[0].Text)
+	}
+	if query != "limit=9876543" {
+		t.Errorf("Expected large coerced integer to be sent verbatim, got: %s", query)
+	}
+}
@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxCompletionValues = 100
+	lookupCacheTTL      = 30 * time.Second
+)
+
+var ErrInvalidCompletionReference = errors.New("invalid completion reference")
+
+type cachedLookup struct {
+	values  []string
+	expires time.Time
+}
+
+// Complete suggests values for a prompt or resource template argument.
+// Candidates come from the argument's enum, from the names of schemas and
+// operations for the built-in templates, or from the lookup operation
+// configured for the argument. Values matching the typed prefix are
+// returned, at most maxCompletionValues of them.
+func (g *MCPGenerator) Complete(ctx context.Context, ref CompletionReference, arg CompletionArgument) (*CompleteResult, error) {
+	var candidates []string
+	var schema interface{}
+
+	switch ref.Type {
+	case "ref/prompt":
+		p := g.findPrompt(ref.Name)
+		if p == nil {
+			return nil, fmt.Errorf("%w: unknown prompt %s", ErrInvalidCompletionReference, ref.Name)
+		}
+		schema = p.schemas[arg.Name]
+	case "ref/resource":
+		switch ref.URI {
+		case schemaResourcePrefix + "{name}":
+			if arg.Name == "name" {
+				candidates = g.schemaNames()
+			}
+		case operationResourcePrefix + "{operationId}":
+			if arg.Name == "operationId" {
+				for _, tool := range g.tools {
+					candidates = append(candidates, tool.Name)
+				}
+			}
+		default:
+			var resource *apiResource
+			for _, r := range g.apiResources {
+				if r.uriTemplate == ref.URI {
+					resource = r
+				}
+			}
+			if resource == nil {
+				return nil, fmt.Errorf("%w: unknown resource template %s", ErrInvalidCompletionReference, ref.URI)
+			}
+			properties, _ := resource.ref.inputSchema["properties"].(map[string]interface{})
+			schema = properties[arg.Name]
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %s", ErrInvalidCompletionReference, ref.Type)
+	}
+
+	if candidates == nil {
+		candidates = enumValues(schema)
+	}
+	if candidates == nil {
+		if lookup, ok := g.config.Lookups[arg.Name]; ok {
+			values, err := g.lookupValues(ctx, arg.Name, lookup)
+			if err != nil {
+				return nil, err
+			}
+			candidates = values
+		}
+	}
+
+	return &CompleteResult{Completion: filterCompletions(candidates, arg.Value)}, nil
+}
+
+func enumValues(schema interface{}) []string {
+	m, _ := schema.(map[string]interface{})
+	if items, ok := m["items"].(map[string]interface{}); ok && m["type"] == "array" {
+		m = items
+	}
+	enum, _ := m["enum"].([]interface{})
+	if len(enum) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(enum))
+	for _, value := range enum {
+		values = append(values, completionValue(value))
+	}
+	return values
+}
+
+// filterCompletions keeps the distinct candidates starting with prefix,
+// compared case-insensitively, and caps the result.
+func filterCompletions(candidates []string, prefix string) Completion {
+	prefix = strings.ToLower(prefix)
+	seen := make(map[string]bool)
+	values := []string{}
+	for _, candidate := range candidates {
+		if seen[candidate] || !strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			continue
+		}
+		seen[candidate] = true
+		values = append(values, candidate)
+	}
+
+	completion := Completion{Values: values, Total: len(values)}
+	if len(values) > maxCompletionValues {
+		completion.Values = values[:maxCompletionValues]
+		completion.HasMore = true
+	}
+	return completion
+}
+
+// lookupValues calls an argument's lookup operation, caching the values it
+// returns for lookupCacheTTL.
+func (g *MCPGenerator) lookupValues(ctx context.Context, name string, lookup LookupConfig) ([]string, error) {
+	g.lookupMu.Lock()
+	cached, ok := g.lookupCache[name]
+	g.lookupMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.values, nil
+	}
+
+	ref := g.operations[lookup.Operation]
+
+	args := make(map[string]interface{})
+	if len(lookup.Arguments) > 0 {
+		data, err := json.Marshal(lookup.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lookup arguments for %s: %w", name, err)
+		}
+		if err := json.Unmarshal(data, &args); err != nil {
+			return nil, fmt.Errorf("invalid lookup arguments for %s: %w", name, err)
+		}
+	}
+	if _, errs := g.prepareArguments(ref, args, true); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid lookup arguments for %s: %s", name, formatValidationErrors(errs))
+	}
+
+	req, err := g.newRequest(ctx, ref, args)
+	if err != nil {
+		return nil, err
+	}
+	resp, data, err := g.send(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("lookup for %s failed with HTTP %d", name, resp.StatusCode)
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("lookup for %s returned invalid JSON: %w", name, err)
+	}
+	body, _ = g.lookupPaths[name].lookup(body)
+	items, ok := body.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("lookup for %s did not return an array", name)
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if lookup.Field != "" {
+			object, _ := item.(map[string]interface{})
+			item = object[lookup.Field]
+		}
+		if item != nil {
+			values = append(values, completionValue(item))
+		}
+	}
+
+	g.lookupMu.Lock()
+	if g.lookupCache == nil {
+		g.lookupCache = make(map[string]cachedLookup)
+	}
+	g.lookupCache[name] = cachedLookup{values: values, expires: time.Now().Add(lookupCacheTTL)}
+	g.lookupMu.Unlock()
+
+	return values, nil
+}
+
+func completionValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,163 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"specmill/parser"
+)
+
+func completionTestSpec(serverURL string) *parser.OpenAPISpec {
+	return &parser.OpenAPISpec{
+		Servers: []parser.Server{{URL: serverURL}},
+		Paths: map[string]parser.PathItem{
+			"/pets": {
+				Get: &parser.Operation{
+					OperationID: "listPets",
+					Parameters: []parser.Parameter{
+						{Name: "status", In: "query", Schema: &parser.Schema{Type: "string", Enum: []interface{}{"available", "pending", "sold"}}},
+					},
+				},
+			},
+			"/pets/{petId}": {
+				Get: &parser.Operation{
+					OperationID: "getPet",
+					Parameters: []parser.Parameter{
+						{Name: "petId", In: "path", Required: true, Schema: &parser.Schema{Type: "integer"}},
+						{Name: "status", In: "query", Schema: &parser.Schema{Type: "string", Enum: []interface{}{"available", "pending", "sold"}}},
+					},
+				},
+			},
+		},
+		Components: &parser.Components{
+			Schemas: map[string]*parser.Schema{"Pet": {Type: "object"}, "Order": {Type: "object"}},
+		},
+	}
+}
+
+func TestComplete(t *testing.T) {
+	calls := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("status") != "available" {
+			t.Errorf("Expected lookup arguments to be sent, got: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"data": {"pet-list": [{"id": 1}, {"id": 12}, {"id": 2}, {"id": 1234567}]}}`))
+	}))
+	defer upstream.Close()
+
+	cfg := &Config{
+		Operations: map[string]OperationConfig{"getPet": {Resource: true}},
+		Lookups: map[string]LookupConfig{
+			"petId": {
+				Operation: "listPets",
+				Arguments: map[string]interface{}{"status": "available"},
+				Path:      "$.data['pet-list']",
+				Field:     "id",
+			},
+		},
+	}
+	gen := NewMCPGeneratorWithConfig(completionTestSpec(upstream.URL), cfg)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		ref      CompletionReference
+		arg      CompletionArgument
+		expected []string
+	}{
+		{
+			name:     "Prompt enum",
+			ref:      CompletionReference{Type: "ref/prompt", Name: "listPets"},
+			arg:      CompletionArgument{Name: "status", Value: "P"},
+			expected: []string{"pending"},
+		},
+		{
+			name:     "Template enum",
+			ref:      CompletionReference{Type: "ref/resource", URI: "specmill://api/pets/{petId}{?status}"},
+			arg:      CompletionArgument{Name: "status"},
+			expected: []string{"available", "pending", "sold"},
+		},
+		{
+			name:     "Lookup",
+			ref:      CompletionReference{Type: "ref/resource", URI: "specmill://api/pets/{petId}{?status}"},
+			arg:      CompletionArgument{Name: "petId", Value: "1"},
+			expected: []string{"1", "12", "1234567"},
+		},
+		{
+			name:     "Cached lookup",
+			ref:      CompletionReference{Type: "ref/prompt", Name: "getPet"},
+			arg:      CompletionArgument{Name: "petId", Value: "2"},
+			expected: []string{"2"},
+		},
+		{
+			name:     "Schema names",
+			ref:      CompletionReference{Type: "ref/resource", URI: "specmill://schemas/{name}"},
+			arg:      CompletionArgument{Name: "name", Value: "p"},
+			expected: []string{"Pet"},
+		},
+		{
+			name:     "No source",
+			ref:      CompletionReference{Type: "ref/prompt", Name: "listPets"},
+			arg:      CompletionArgument{Name: "limit"},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := gen.Complete(context.Background(), tt.ref, tt.arg)
+			if err != nil {
+				t.Fatalf("Failed to complete: %v", err)
+			}
+			if !reflect.DeepEqual(result.Completion.Values, tt.expected) {
+				t.Errorf("Expected %v, got: %v", tt.expected, result.Completion.Values)
+			}
+		})
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected lookup to be cached, got %d upstream calls", calls)
+	}
+
+	for _, ref := range []CompletionReference{
+		{Type: "ref/prompt", Name: "missing"},
+		{Type: "ref/resource", URI: "specmill://api/missing"},
+		{Type: "ref/tool", Name: "getPet"},
+	} {
+		if _, err := gen.Complete(context.Background(), ref, CompletionArgument{Name: "x"}); !errors.Is(err, ErrInvalidCompletionReference) {
+			t.Errorf("Expected ErrInvalidCompletionReference for %+v, got: %v", ref, err)
+		}
+	}
+}
+
+func TestLookupPathValidation(t *testing.T) {
+	for path, valid := range map[string]bool{"": true, "data": true, "$.data.items[0]": true, "$.data[": false, "$..data": false} {
+		cfg := &Config{Lookups: map[string]LookupConfig{
+			"petId": {Operation: "listPets", Path: path},
+		}}
+		err := NewMCPGeneratorWithConfig(completionTestSpec("https://api.example.com"), cfg).GenerateTools()
+		if (err == nil) != valid {
+			t.Errorf("Path %q: expected valid=%v, got: %v", path, valid, err)
+		}
+	}
+}
+
+func TestFilterCompletionsCap(t *testing.T) {
+	candidates := make([]string, 150)
+	for i := range candidates {
+		candidates[i] = fmt.Sprintf("value-%d", i)
+	}
+
+	completion := filterCompletions(candidates, "VALUE")
+	if len(completion.Values) != maxCompletionValues || completion.Total != 150 || !completion.HasMore {
+		t.Errorf("Expected %d of 150 values with hasMore, got: %d of %d (hasMore: %v)", maxCompletionValues, len(completion.Values), completion.Total, completion.HasMore)
+	}
+}
@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	CoerceArguments bool                       `yaml:"coerceArguments"`
+	FlattenBody     bool                       `yaml:"flattenBody"`
+	Pinned          map[string]interface{}     `yaml:"pinned"`
+	PollInterval    time.Duration              `yaml:"pollInterval"`
+	Operations      map[string]OperationConfig `yaml:"operations"`
+	Prompts         []PromptConfig             `yaml:"prompts"`
+	Lookups         map[string]LookupConfig    `yaml:"lookups"`
+	Streaming       StreamingConfig            `yaml:"streaming"`
+}
+
+// StreamingConfig limits how much of a text/event-stream or NDJSON response
+// is read before the call returns with the events received so far.
+type StreamingConfig struct {
+	MaxEvents   int           `yaml:"maxEvents"`
+	MaxDuration time.Duration `yaml:"maxDuration"`
+}
+
+// LookupConfig designates an operation whose response supplies completion
+// values for an argument. The response must be a JSON array, or hold one at
+// the JSONPath Path, such as $.data.items; Field selects a property of each
+// item.
+type LookupConfig struct {
+	Operation string                 `yaml:"operation"`
+	Arguments map[string]interface{} `yaml:"arguments"`
+	Path      string                 `yaml:"path"`
+	Field     string                 `yaml:"field"`
+}
+
+// PromptConfig defines a prompt template. Occurrences of {{name}} in
+// Template are replaced with the argument of that name, and the tools listed
+// in Tools are described after the rendered text.
+type PromptConfig struct {
+	Name        string           `yaml:"name"`
+	Description string           `yaml:"description"`
+	Arguments   []PromptArgument `yaml:"arguments"`
+	Tools       []string         `yaml:"tools"`
+	Template    string           `yaml:"template"`
+}
+
+type OperationConfig struct {
+	FlattenBody  *bool                  `yaml:"flattenBody"`
+	Pinned       map[string]interface{} `yaml:"pinned"`
+	Resource     bool                   `yaml:"resource"`
+	PollInterval time.Duration          `yaml:"pollInterval"`
+	Polling      *PollingConfig         `yaml:"polling"`
+	Streaming    *StreamingConfig       `yaml:"streaming"`
+	Pagination   *PaginationConfig      `yaml:"pagination"`
+}
+
+// PaginationConfig makes ExecuteTool follow a paginated operation's pages
+// and return their items in a single result. Strategy is one of link (RFC
+// 8288 Link header with rel="next"), cursor (NextCursor JSONPath in the body,
+// sent back as CursorParam), offset (OffsetParam/LimitParam) or page
+// (PageParam/PageSizeParam). Items is the JSONPath of the items in a page.
+type PaginationConfig struct {
+	Strategy      string `yaml:"strategy"`
+	Items         string `yaml:"items"`
+	NextCursor    string `yaml:"nextCursor"`
+	CursorParam   string `yaml:"cursorParam"`
+	OffsetParam   string `yaml:"offsetParam"`
+	LimitParam    string `yaml:"limitParam"`
+	PageParam     string `yaml:"pageParam"`
+	PageSizeParam string `yaml:"pageSizeParam"`
+	FirstPage     *int   `yaml:"firstPage"`
+	MaxPages      int    `yaml:"maxPages"`
+	MaxItems      int    `yaml:"maxItems"`
+}
+
+// PollingConfig makes ExecuteTool follow 202 Accepted responses carrying an
+// Operation-Location or Location header: the status URL is polled with
+// exponential backoff, honoring Retry-After, until the value at the Status
+// JSONPath is one of Terminal. Zero fields take the defaults below.
+type PollingConfig struct {
+	Status      string        `yaml:"status"`
+	Terminal    []string      `yaml:"terminal"`
+	Failed      []string      `yaml:"failed"`
+	Result      string        `yaml:"result"`
+	Interval    time.Duration `yaml:"interval"`
+	MaxInterval time.Duration `yaml:"maxInterval"`
+	Timeout     time.Duration `yaml:"timeout"`
+}
+
+func (c *Config) flattenBody(operationID string) bool {
+	if op, ok := c.Operations[operationID]; ok && op.FlattenBody != nil {
+		return *op.FlattenBody
+	}
+	return c.FlattenBody
+}
+
+// pinnedArguments returns the arguments pinned for an operation: global pins
+// that match one of its input properties, overridden by the operation's own
+// pins. Values are normalized to their JSON representation.
+func (c *Config) pinnedArguments(operationID string, properties map[string]interface{}) map[string]interface{} {
+	pinned := make(map[string]interface{})
+	for name, value := range c.Pinned {
+		if _, ok := properties[name]; ok {
+			pinned[name] = value
+		}
+	}
+	for name, value := range c.Operations[operationID].Pinned {
+		pinned[name] = value
+	}
+	if len(pinned) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(pinned)
+	if err != nil {
+		return nil
+	}
+	normalized := make(map[string]interface{})
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil
+	}
+	return normalized
+}
+
+// operationExtension decodes an operation's x-specmill extension.
+func operationExtension(extension map[string]interface{}) (OperationConfig, error) {
+	var cfg OperationConfig
+	if len(extension) == 0 {
+		return cfg, nil
+	}
+	data, err := yaml.Marshal(extension)
+	if err != nil {
+		return cfg, err
+	}
+	err = yaml.Unmarshal(data, &cfg)
+	return cfg, err
+}
+
+func LoadConfig(filePath string) (*Config, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "specmill.yaml")
+
+	err := os.WriteFile(configFile, []byte("coerceArguments: true\npollInterval: 45s\noperations:\n  getPet:\n    pollInterval: 5s\n"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cfg, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !cfg.CoerceArguments {
+		t.Error("Expected coerceArguments to be enabled")
+	}
+
+	if cfg.PollInterval != 45*time.Second {
+		t.Errorf("Expected pollInterval 45s, got: %v", cfg.PollInterval)
+	}
+
+	if cfg.Operations["getPet"].PollInterval != 5*time.Second {
+		t.Errorf("Expected getPet pollInterval 5s, got: %v", cfg.Operations["getPet"].PollInterval)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	if _, err := LoadConfig("nonexistent.yaml"); err == nil {
+		t.Error("Expected error for non-existent file")
+	}
+
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "invalid.yaml")
+	if err := os.WriteFile(configFile, []byte("coerceArguments: [\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if _, err := LoadConfig(configFile); err == nil {
+		t.Error("Expected error for invalid YAML")
+	}
+}
+
+func TestPinnedArguments(t *testing.T) {
+	cfg := &Config{
+		Pinned: map[string]interface{}{"tenantId": "acme", "region": "us"},
+		Operations: map[string]OperationConfig{
+			"listPets": {Pinned: map[string]interface{}{"region": "eu", "limit": 10}},
+		},
+	}
+
+	properties := map[string]interface{}{"tenantId": nil, "limit": nil}
+
+	pinned := cfg.pinnedArguments("listPets", properties)
+	if pinned["tenantId"] != "acme" {
+		t.Errorf("Expected global pin for tenantId, got: %v", pinned["tenantId"])
+	}
+	if pinned["region"] != "eu" {
+		t.Errorf("Expected operation pin to override global pin, got: %v", pinned["region"])
+	}
+	if pinned["limit"] != float64(10) {
+		t.Errorf("Expected pinned values normalized to JSON numbers, got: %T", pinned["limit"])
+	}
+
+	if pinned := cfg.pinnedArguments("getPet", map[string]interface{}{}); pinned != nil {
+		t.Errorf("Expected no pins for operation without matching properties, got: %v", pinned)
+	}
+}
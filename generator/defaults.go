@@ -0,0 +1,52 @@
+package generator
+
+import "sort"
+
+type DefaultedArgument struct {
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// applyDefaults fills in schema defaults for properties the caller omitted,
+// descending into nested objects and arrays that are present. value is
+// modified in place.
+func applyDefaults(schema map[string]interface{}, value interface{}, path string) []DefaultedArgument {
+	if schema == nil {
+		return nil
+	}
+
+	var applied []DefaultedArgument
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			propSchema, ok := properties[name].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if current, present := v[name]; present {
+				applied = append(applied, applyDefaults(propSchema, current, childPath(path, name))...)
+				continue
+			}
+			if def, ok := propSchema["default"]; ok {
+				v[name] = def
+				applied = append(applied, DefaultedArgument{Path: childPath(path, name), Value: def})
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				applied = append(applied, applyDefaults(items, item, indexPath(path, i))...)
+			}
+		}
+	}
+
+	return applied
+}
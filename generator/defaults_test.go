@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"specmill/parser"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	schema := map[string]interface{}{}
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"status": {"type": "string", "default": "available"},
+			"limit": {"type": "integer", "default": 20},
+			"body": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string"},
+					"kind": {"type": "string", "default": "dog"},
+					"tags": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"properties": {"weight": {"type": "integer", "default": 1}}
+						}
+					}
+				}
+			}
+		}
+	}`), &schema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+
+	var args, expected map[string]interface{}
+	json.Unmarshal([]byte(`{"limit": 5, "body": {"name": "Rex", "tags": [{}, {"weight": 3}]}}`), &args)
+	json.Unmarshal([]byte(`{"status": "available", "limit": 5, "body": {"name": "Rex", "kind": "dog", "tags": [{"weight": 1}, {"weight": 3}]}}`), &expected)
+
+	applied := applyDefaults(schema, args, "$")
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected %v, got: %v", expected, args)
+	}
+
+	paths := []string{}
+	for _, a := range applied {
+		paths = append(paths, a.Path)
+	}
+	expectedPaths := []string{"$.body.kind", "$.body.tags[0].weight", "$.status"}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Errorf("Expected defaults at %v, got: %v", expectedPaths, paths)
+	}
+}
+
+func TestExecuteToolDefaultsAndPins(t *testing.T) {
+	var received *http.Request
+	var receivedBody map[string]interface{}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		data, _ := io.ReadAll(r.Body)
+		receivedBody = nil
+		json.Unmarshal(data, &receivedBody)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer upstream.Close()
+
+	spec := &parser.OpenAPISpec{
+		Servers: []parser.Server{{URL: upstream.URL}},
+		Paths: map[string]parser.PathItem{
+			"/tenants/{tenantId}/pets": {
+				Post: &parser.Operation{
+					OperationID: "createPet",
+					Parameters: []parser.Parameter{
+						{Name: "tenantId", In: "path", Required: true, Schema: &parser.Schema{Type: "string"}},
+						{Name: "region", In: "query", Required: true, Schema: &parser.Schema{Type: "string"}},
+						{Name: "X-Trace", In: "header", Schema: &parser.Schema{Type: "string", Default: "off"}},
+					},
+					RequestBody: &parser.RequestBody{
+						Required: true,
+						Content: map[string]parser.MediaType{
+							"application/json": {
+								Schema: &parser.Schema{
+									Type: "object",
+									Properties: map[string]*parser.Schema{
+										"name":   {Type: "string"},
+										"status": {Type: "string", Default: "available"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cfg := &Config{
+		Pinned: map[string]interface{}{"tenantId": "acme", "unrelated": 1},
+		Operations: map[string]OperationConfig{
+			"createPet": {Pinned: map[string]interface{}{"region": "eu-west-1"}},
+		},
+	}
+
+	gen := NewMCPGeneratorWithConfig(spec, cfg)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(gen.GetTools()[0].InputSchema, &schema); err != nil {
+		t.Fatalf("Failed to unmarshal schema: %v", err)
+	}
+	props := schema["properties"].(map[string]interface{})
+	for _, hidden := range []string{"tenantId", "region", "unrelated"} {
+		if _, ok := props[hidden]; ok {
+			t.Errorf("Pinned argument '%s' should not be advertised", hidden)
+		}
+	}
+	required := schema["required"].([]interface{})
+	if len(required) != 1 || required[0] != "body" {
+		t.Errorf("Expected only 'body' to be required, got: %v", required)
+	}
+
+	result, err := gen.ExecuteTool(context.Background(), "createPet", json.RawMessage(`{"tenantId": "evil", "body": {"name": "Rex"}}`))
+	if err != nil {
+		t.Fatalf("Failed to execute tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected call to succeed, got: %s", result.Content[0].Text)
+	}
+
+	if received.URL.Path != "/tenants/acme/pets" {
+		t.Errorf("Expected pinned tenant in path, got: %s", received.URL.Path)
+	}
+	if received.URL.Query().Get("region") != "eu-west-1" {
+		t.Errorf("Expected pinned region in query, got: %s", received.URL.RawQuery)
+	}
+	if received.Header.Get("X-Trace") != "off" {
+		t.Errorf("Expected default header value, got: %s", received.Header.Get("X-Trace"))
+	}
+	if received.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON content type, got: %s", received.Header.Get("Content-Type"))
+	}
+	if receivedBody["name"] != "Rex" || receivedBody["status"] != "available" {
+		t.Errorf("Expected body with default status, got: %v", receivedBody)
+	}
+
+	defaults, ok := result.Meta["specmill/defaults"].([]DefaultedArgument)
+	if !ok || len(defaults) != 2 {
+		t.Errorf("Expected two defaults in result metadata, got: %v", result.Meta)
+	}
+}
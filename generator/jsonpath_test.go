@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONPath(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{
+		"status": "running",
+		"data": {"items": [{"id": 1}, {"id": 2}]},
+		"next-page": "abc"
+	}`), &doc); err != nil {
+		t.Fatalf("Failed to parse document: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected interface{}
+		found    bool
+		wantErr  bool
+	}{
+		{path: "$.status", expected: "running", found: true},
+		{path: "status", expected: "running", found: true},
+		{path: "$.data.items[1].id", expected: float64(2), found: true},
+		{path: "$.data.items[-1].id", expected: float64(2), found: true},
+		{path: "$['next-page']", expected: "abc", found: true},
+		{path: `$["data"]["items"][0].id`, expected: float64(1), found: true},
+		{path: "$.data.items[5]", found: false},
+		{path: "$.missing.field", found: false},
+		{path: "$.status.length", found: false},
+		{path: "$.data.items[*]", wantErr: true},
+		{path: "$..status", wantErr: true},
+		{path: "$.data[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := parseJSONPath(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %s", tt.path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse path: %v", err)
+			}
+
+			value, found := path.lookup(doc)
+			if found != tt.found {
+				t.Fatalf("Expected found %v, got: %v", tt.found, found)
+			}
+			if found && value != tt.expected {
+				t.Errorf("Expected %v, got: %v", tt.expected, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"specmill/parser"
+)
+
+const (
+	defaultPollingStatus      = "$.status"
+	defaultPollingInterval    = time.Second
+	defaultPollingMaxInterval = 30 * time.Second
+	defaultPollingTimeout     = 10 * time.Minute
+)
+
+var (
+	defaultTerminalStatuses = []string{"succeeded", "failed", "canceled", "cancelled", "completed"}
+	defaultFailedStatuses   = []string{"failed", "canceled", "cancelled"}
+)
+
+// pollingRule is a validated PollingConfig with defaults applied. Status
+// values are compared case-insensitively.
+type pollingRule struct {
+	status      jsonPath
+	terminal    map[string]bool
+	failed      map[string]bool
+	result      jsonPath
+	interval    time.Duration
+	maxInterval time.Duration
+	timeout     time.Duration
+}
+
+// pollingRule returns the polling rule of an operation, taken from the config
+// or else from the operation's x-specmill extension, or nil when 202
+// responses are returned as they are.
+func (g *MCPGenerator) pollingRule(op *parser.Operation) (*pollingRule, error) {
+	cfg := g.config.Operations[op.OperationID].Polling
+	if cfg == nil {
+		extension, err := operationExtension(op.Specmill)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x-specmill extension for %s: %w", op.OperationID, err)
+		}
+		cfg = extension.Polling
+	}
+	if cfg == nil {
+		return nil, nil
+	}
+
+	rule, err := newPollingRule(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid polling config for %s: %w", op.OperationID, err)
+	}
+	return rule, nil
+}
+
+func newPollingRule(cfg *PollingConfig) (*pollingRule, error) {
+	rule := &pollingRule{
+		terminal:    statusSet(cfg.Terminal, defaultTerminalStatuses),
+		failed:      statusSet(cfg.Failed, defaultFailedStatuses),
+		interval:    cfg.Interval,
+		maxInterval: cfg.MaxInterval,
+		timeout:     cfg.Timeout,
+	}
+
+	status := cfg.Status
+	if status == "" {
+		status = defaultPollingStatus
+	}
+	var err error
+	if rule.status, err = parseJSONPath(status); err != nil {
+		return nil, err
+	}
+	if cfg.Result != "" {
+		if rule.result, err = parseJSONPath(cfg.Result); err != nil {
+			return nil, err
+		}
+	}
+
+	if rule.interval <= 0 {
+		rule.interval = defaultPollingInterval
+	}
+	if rule.maxInterval <= 0 {
+		rule.maxInterval = defaultPollingMaxInterval
+	}
+	if rule.maxInterval < rule.interval {
+		rule.maxInterval = rule.interval
+	}
+	if rule.timeout <= 0 {
+		rule.timeout = defaultPollingTimeout
+	}
+	return rule, nil
+}
+
+func statusSet(values, defaults []string) map[string]bool {
+	if len(values) == 0 {
+		values = defaults
+	}
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[strings.ToLower(value)] = true
+	}
+	return set
+}
+
+// evaluate reads the status of a poll response and reports whether the
+// operation has finished. A response without a status is final unless it is
+// another 202.
+func (r *pollingRule) evaluate(statusCode int, data []byte) (string, bool) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err == nil {
+		if value, ok := r.status.lookup(doc); ok && value != nil {
+			status := fmt.Sprint(value)
+			return status, r.terminal[strings.ToLower(status)]
+		}
+	}
+	return "", statusCode != http.StatusAccepted
+}
+
+// operationURLs returns the URL to poll for a 202 response and, when the
+// response names a separate Location for the result, that URL too.
+func operationURLs(resp *http.Response) (string, string) {
+	resolve := func(ref string) string {
+		if ref == "" || resp.Request == nil {
+			return ref
+		}
+		u, err := resp.Request.URL.Parse(ref)
+		if err != nil {
+			return ""
+		}
+		return u.String()
+	}
+
+	location := resolve(resp.Header.Get("Location"))
+	if status := resolve(resp.Header.Get("Operation-Location")); status != "" {
+		return status, location
+	}
+	return location, ""
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// awaitOperation polls the status URL of a 202 Accepted response until the
+// operation reaches a terminal state or the rule's timeout expires, and
+// returns the final resource. It also returns metadata describing the
+// polling.
+func (g *MCPGenerator) awaitOperation(ctx context.Context, rule *pollingRule, resp *http.Response, data []byte) (*CallToolResult, map[string]any, error) {
+	statusURL, location := operationURLs(resp)
+	if statusURL == "" {
+		return newResponseResult(resp.StatusCode, data), nil, nil
+	}
+
+	meta := map[string]any{"statusUrl": statusURL}
+	tracker := progressFromContext(ctx)
+	deadline := time.Now().Add(rule.timeout)
+
+	// Retry-After may lengthen the wait but never shortens it below the
+	// configured interval.
+	delay := rule.interval
+	if d, ok := retryAfter(resp.Header); ok {
+		delay = max(d, rule.interval)
+	}
+
+	status := ""
+	for attempt := 1; ; attempt++ {
+		if time.Now().Add(delay).After(deadline) {
+			result := &CallToolResult{
+				Content: []ToolContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Operation still in progress after %s (last status %q). Status URL: %s", rule.timeout, status, statusURL),
+				}},
+				IsError: true,
+			}
+			return result, meta, nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		pollResp, body, err := g.get(ctx, statusURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		meta["attempts"] = attempt
+
+		var done bool
+		status, done = rule.evaluate(pollResp.StatusCode, body)
+		if status != "" {
+			meta["status"] = status
+		}
+		tracker.step(fmt.Sprintf("Polled operation status (attempt %d): %s", attempt, statusOrCode(status, pollResp.StatusCode)))
+
+		if pollResp.StatusCode >= 400 {
+			return newResponseResult(pollResp.StatusCode, body), meta, nil
+		}
+		if done {
+			result, err := g.operationResult(ctx, rule, status, pollResp, body, location)
+			return result, meta, err
+		}
+
+		delay = min(delay*2, rule.maxInterval)
+		if d, ok := retryAfter(pollResp.Header); ok {
+			delay = max(d, rule.interval)
+		}
+	}
+}
+
+func statusOrCode(status string, code int) string {
+	if status != "" {
+		return status
+	}
+	return fmt.Sprintf("HTTP %d", code)
+}
+
+// operationResult builds the result of a finished operation. Failed
+// operations return the status document as an error; successful ones return
+// the resource named by the rule's result path or the separate Location
+// header, falling back to the status document.
+func (g *MCPGenerator) operationResult(ctx context.Context, rule *pollingRule, status string, resp *http.Response, data []byte, location string) (*CallToolResult, error) {
+	if rule.failed[strings.ToLower(status)] {
+		return &CallToolResult{
+			Content: []ToolContent{{Type: "text", Text: fmt.Sprintf("Operation %s: %s", status, data)}},
+			IsError: true,
+		}, nil
+	}
+
+	if rule.result != nil {
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err == nil {
+			if value, ok := rule.result.lookup(doc); ok {
+				if ref, ok := value.(string); ok && ref != "" {
+					if u, err := resp.Request.URL.Parse(ref); err == nil {
+						location = u.String()
+					}
+				}
+			}
+		}
+	}
+	if location == "" {
+		return newResponseResult(resp.StatusCode, data), nil
+	}
+
+	final, body, err := g.get(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	return newResponseResult(final.StatusCode, body), nil
+}
+
+// get fetches a URL given by the upstream, such as an operation status
+// monitor, with the configured request editor applied. URLs on other hosts
+// are refused so that credentials are only ever sent to the upstream.
+func (g *MCPGenerator) get(ctx context.Context, target string) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if base, err := url.Parse(g.baseURL); err != nil || req.URL.Host != base.Host {
+		return nil, nil, fmt.Errorf("refusing to fetch %s: not on the upstream host", target)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if g.requestEditor != nil {
+		if err := g.requestEditor(req); err != nil {
+			return nil, nil, fmt.Errorf("failed to prepare request: %w", err)
+		}
+	}
+
+	return g.send(req)
+}
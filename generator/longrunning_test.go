@@ -0,0 +1,272 @@
+package generator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"specmill/parser"
+)
+
+func longRunningTestSpec(serverURL, extension string) string {
+	return `
+openapi: 3.0.0
+info:
+  title: Jobs API
+  version: 1.0.0
+servers:
+  - url: ` + serverURL + `
+paths:
+  /jobs:
+    post:
+      operationId: createJob
+      responses:
+        "202":
+          description: Accepted
+` + extension
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "", ok: false},
+		{value: "3", expected: 3 * time.Second, ok: true},
+		{value: "-1", expected: 0, ok: true},
+		{value: "Mon, 01 Jan 2001 00:00:00 GMT", expected: 0, ok: true},
+		{value: "soon", ok: false},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.value != "" {
+			header.Set("Retry-After", tt.value)
+		}
+		delay, ok := retryAfter(header)
+		if ok != tt.ok || delay != tt.expected {
+			t.Errorf("Expected %v, %v for %q, got: %v, %v", tt.expected, tt.ok, tt.value, delay, ok)
+		}
+	}
+}
+
+func TestExecuteToolLongRunning(t *testing.T) {
+	tests := []struct {
+		name      string
+		extension string
+		statuses  []string
+		isError   bool
+		expected  string
+		attempts  int32
+	}{
+		{
+			name:     "Without polling",
+			statuses: []string{"succeeded"},
+			expected: `"queued"`,
+		},
+		{
+			name: "Succeeded",
+			extension: `      x-specmill:
+        polling:
+          interval: 1ms
+`,
+			statuses: []string{"running", "Succeeded"},
+			expected: `"id":"job-1"`,
+			attempts: 2,
+		},
+		{
+			name: "Custom status path",
+			extension: `      x-specmill:
+        polling:
+          status: $.state.phase
+          terminal: [ready]
+          interval: 1ms
+`,
+			statuses: []string{"running", "ready"},
+			expected: `"id":"job-1"`,
+			attempts: 2,
+		},
+		{
+			name: "Failed",
+			extension: `      x-specmill:
+        polling:
+          interval: 1ms
+`,
+			statuses: []string{"running", "failed"},
+			isError:  true,
+			expected: "Operation failed",
+			attempts: 2,
+		},
+		{
+			name: "Timeout",
+			extension: `      x-specmill:
+        polling:
+          interval: 5ms
+          timeout: 20ms
+`,
+			statuses: []string{"running"},
+			isError:  true,
+			expected: "still in progress",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var polls atomic.Int32
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/jobs":
+					w.Header().Set("Operation-Location", "/operations/1")
+					w.Header().Set("Location", "/jobs/1")
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusAccepted)
+					w.Write([]byte(`{"status":"queued"}`))
+				case "/operations/1":
+					n := int(polls.Add(1))
+					status := tt.statuses[min(n, len(tt.statuses))-1]
+					w.Write([]byte(`{"status":"` + status + `","state":{"phase":"` + status + `"}}`))
+				case "/jobs/1":
+					w.Write([]byte(`{"id":"job-1"}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer upstream.Close()
+
+			spec, err := parser.ParseOpenAPISpecData([]byte(longRunningTestSpec(upstream.URL, tt.extension)))
+			if err != nil {
+				t.Fatalf("Failed to parse spec: %v", err)
+			}
+			gen := NewMCPGenerator(spec)
+			if err := gen.GenerateTools(); err != nil {
+				t.Fatalf("Failed to generate tools: %v", err)
+			}
+
+			var steps []string
+			ctx := WithProgress(context.Background(), func(progress, total float64, message string) {
+				if strings.HasPrefix(message, "Polled") {
+					steps = append(steps, message)
+				}
+			})
+
+			result, err := gen.ExecuteTool(ctx, "createJob", nil)
+			if err != nil {
+				t.Fatalf("Failed to execute tool: %v", err)
+			}
+			if result.IsError != tt.isError {
+				t.Errorf("Expected isError %v, got: %s", tt.isError, result.Content[0].Text)
+			}
+			if !strings.Contains(result.Content[0].Text, tt.expected) {
+				t.Errorf("Expected result to contain %s, got: %s", tt.expected, result.Content[0].Text)
+			}
+			if tt.attempts > 0 {
+				if polls.Load() != tt.attempts {
+					t.Errorf("Expected %d polls, got: %d", tt.attempts, polls.Load())
+				}
+				if len(steps) != int(tt.attempts) {
+					t.Errorf("Expected a progress step per poll, got: %v", steps)
+				}
+				polling, _ := result.Meta["specmill/polling"].(map[string]any)
+				if polling["attempts"] != int(tt.attempts) || !strings.HasSuffix(polling["statusUrl"].(string), "/operations/1") {
+					t.Errorf("Unexpected polling metadata: %v", polling)
+				}
+			}
+		})
+	}
+}
+
+func TestPollingRetryAfterFloor(t *testing.T) {
+	var polls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "0")
+		if r.URL.Path == "/jobs" {
+			w.Header().Set("Location", "/operations/1")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		polls.Add(1)
+		w.Write([]byte(`{"status":"running"}`))
+	}))
+	defer upstream.Close()
+
+	spec, err := parser.ParseOpenAPISpecData([]byte(longRunningTestSpec(upstream.URL, `      x-specmill:
+        polling:
+          interval: 20ms
+          timeout: 100ms
+`)))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	gen := NewMCPGenerator(spec)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	result, err := gen.ExecuteTool(context.Background(), "createJob", nil)
+	if err != nil {
+		t.Fatalf("Failed to execute tool: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "still in progress") {
+		t.Errorf("Expected timeout, got: %s", result.Content[0].Text)
+	}
+	if n := polls.Load(); n == 0 || n > 5 {
+		t.Errorf("Expected Retry-After: 0 to keep the configured interval, got %d polls", n)
+	}
+}
+
+func TestPollingCrossHost(t *testing.T) {
+	var foreignRequests atomic.Int32
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		foreignRequests.Add(1)
+		w.Write([]byte(`{"status":"succeeded"}`))
+	}))
+	defer foreign.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Operation-Location", foreign.URL+"/operations/1")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer upstream.Close()
+
+	spec, err := parser.ParseOpenAPISpecData([]byte(longRunningTestSpec(upstream.URL, `      x-specmill:
+        polling:
+          interval: 1ms
+`)))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	gen := NewMCPGenerator(spec)
+	gen.SetRequestEditor(BearerAuth("secret"))
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	_, err = gen.ExecuteTool(context.Background(), "createJob", nil)
+	if err == nil || !strings.Contains(err.Error(), "not on the upstream host") {
+		t.Errorf("Expected cross-host status URL to be refused, got: %v", err)
+	}
+	if foreignRequests.Load() != 0 {
+		t.Errorf("Expected no request to the foreign host, got: %d", foreignRequests.Load())
+	}
+}
+
+func TestPollingConfigValidation(t *testing.T) {
+	spec, err := parser.ParseOpenAPISpecData([]byte(longRunningTestSpec("http://localhost", "")))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	cfg := &Config{Operations: map[string]OperationConfig{
+		"createJob": {Polling: &PollingConfig{Status: "$.items[*]"}},
+	}}
+	gen := NewMCPGeneratorWithConfig(spec, cfg)
+	if err := gen.GenerateTools(); err == nil || !strings.Contains(err.Error(), "createJob") {
+		t.Errorf("Expected invalid polling config error, got: %v", err)
+	}
+}
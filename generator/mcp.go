@@ -1,54 +1,200 @@
 package generator
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"specmill/parser"
 )
 
+const maxResponseBytes = 10 << 20
+
 type MCPGenerator struct {
-	spec      *parser.OpenAPISpec
-	tools     []MCPTool
-	baseURL   string
-	client    *http.Client
+	spec          *parser.OpenAPISpec
+	config        *Config
+	tools         []MCPTool
+	operations    map[string]*operationRef
+	baseURL       string
+	client        *http.Client
+	requestEditor RequestEditor
+	filter        OperationFilter
+	rawSpec       []byte
+	apiResources  []*apiResource
+	prompts       []*prompt
+
+	// lookupPaths holds the parsed Path of each configured lookup.
+	lookupPaths map[string]jsonPath
+	lookupMu    sync.Mutex
+	lookupCache map[string]cachedLookup
+}
+
+// OperationFilter decides whether an operation is exposed as a tool.
+type OperationFilter func(method, path string, op *parser.Operation) bool
+
+type operationRef struct {
+	method      string
+	path        string
+	operation   *parser.Operation
+	inputSchema map[string]interface{}
+	pinned      map[string]interface{}
+	bodyFields  map[string]string
+	polling     *pollingRule
+	streaming   StreamingConfig
+	pagination  *paginationRule
+	// structured is set for tools advertising an output schema, whose
+	// successful results must carry structured content.
+	structured bool
 }
 
 func NewMCPGenerator(spec *parser.OpenAPISpec) *MCPGenerator {
+	return NewMCPGeneratorWithConfig(spec, &Config{})
+}
+
+func NewMCPGeneratorWithConfig(spec *parser.OpenAPISpec, cfg *Config) *MCPGenerator {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
 	baseURL := ""
 	if len(spec.Servers) > 0 {
 		baseURL = spec.Servers[0].URL
 	}
 
 	return &MCPGenerator{
-		spec:    spec,
-		tools:   []MCPTool{},
-		baseURL: baseURL,
-		client:  &http.Client{},
+		spec:       spec,
+		config:     cfg,
+		tools:      []MCPTool{},
+		operations: make(map[string]*operationRef),
+		baseURL:    baseURL,
+		client:     &http.Client{},
 	}
 }
 
+func (g *MCPGenerator) SetHTTPClient(client *http.Client) {
+	if client != nil {
+		g.client = client
+	}
+}
+
+func (g *MCPGenerator) SetRequestEditor(editor RequestEditor) {
+	g.requestEditor = editor
+}
+
+// SetOperationFilter restricts the tools produced by subsequent calls to
+// GenerateTools.
+func (g *MCPGenerator) SetOperationFilter(filter OperationFilter) {
+	g.filter = filter
+}
+
 func (g *MCPGenerator) GenerateTools() error {
-	for path, pathItem := range g.spec.Paths {
+	g.tools = []MCPTool{}
+	g.operations = make(map[string]*operationRef)
+	g.apiResources = nil
+
+	for _, path := range g.spec.SortedPaths() {
+		pathItem := g.spec.Paths[path]
 		operations := pathItem.GetOperations()
-		for method, operation := range operations {
-			if operation.OperationID == "" {
+		for _, method := range parser.Methods {
+			operation, ok := operations[method]
+			if !ok || operation.OperationID == "" {
+				continue
+			}
+			if g.filter != nil && !g.filter(method, path, operation) {
 				continue
 			}
+			if existing, exists := g.operations[operation.OperationID]; exists {
+				return fmt.Errorf("duplicate operationId %s: used by %s %s and %s %s", operation.OperationID,
+					strings.ToUpper(existing.method), existing.path, strings.ToUpper(method), path)
+			}
+
+			fullSchema, bodyFields := g.generateInputSchema(operation)
+
+			pagination, err := g.paginationRule(operation)
+			if err != nil {
+				return err
+			}
+			if pagination != nil {
+				fullSchema = addContinuationArgument(fullSchema)
+			}
+
+			var inputSchema map[string]interface{}
+			if err := json.Unmarshal(fullSchema, &inputSchema); err != nil {
+				return fmt.Errorf("invalid input schema for %s: %w", operation.OperationID, err)
+			}
+
+			properties, _ := inputSchema["properties"].(map[string]interface{})
+			pinned := g.config.pinnedArguments(operation.OperationID, properties)
 
 			tool := MCPTool{
-				Name:        operation.OperationID,
-				Description: g.generateDescription(operation, method, path),
-				InputSchema: g.generateInputSchema(operation),
+				Name:         operation.OperationID,
+				Title:        operation.Summary,
+				Description:  g.generateDescription(operation, method, path),
+				InputSchema:  hideArguments(fullSchema, pinned),
+				OutputSchema: g.generateOutputSchema(operation),
+				Annotations:  generateAnnotations(method),
+			}
+			if pagination != nil {
+				// Collected pages do not match the schema of a single page.
+				tool.OutputSchema = nil
+				tool.Description += "\n" + pagination.description()
+			}
+
+			ref := &operationRef{
+				method:      method,
+				path:        path,
+				operation:   operation,
+				inputSchema: inputSchema,
+				pinned:      pinned,
+				bodyFields:  bodyFields,
+				pagination:  pagination,
+				structured:  tool.OutputSchema != nil,
+			}
+			polling, err := g.pollingRule(operation)
+			if err != nil {
+				return err
+			}
+			ref.polling = polling
+			if ref.streaming, err = g.streamLimits(operation); err != nil {
+				return err
+			}
+			g.operations[operation.OperationID] = ref
+
+			if g.config.Operations[operation.OperationID].Resource {
+				if method != "get" {
+					return fmt.Errorf("operation %s cannot be a resource: only GET operations are supported", operation.OperationID)
+				}
+				g.apiResources = append(g.apiResources, newAPIResource(ref))
 			}
 
 			g.tools = append(g.tools, tool)
 		}
 	}
 
-	return nil
+	g.lookupPaths = make(map[string]jsonPath)
+	for name, lookup := range g.config.Lookups {
+		if _, ok := g.operations[lookup.Operation]; !ok {
+			return fmt.Errorf("lookup for %s references unknown operation %s", name, lookup.Operation)
+		}
+		path, err := parseJSONPath(lookup.Path)
+		if err != nil {
+			return fmt.Errorf("lookup for %s: %w", name, err)
+		}
+		g.lookupPaths[name] = path
+	}
+	g.lookupMu.Lock()
+	g.lookupCache = nil
+	g.lookupMu.Unlock()
+
+	return g.generatePrompts()
 }
 
 func (g *MCPGenerator) generateDescription(op *parser.Operation, method, path string) string {
@@ -62,7 +208,11 @@ func (g *MCPGenerator) generateDescription(op *parser.Operation, method, path st
 	return desc
 }
 
-func (g *MCPGenerator) generateInputSchema(op *parser.Operation) json.RawMessage {
+// generateInputSchema builds a tool's input schema from an operation's
+// parameters and JSON request body. When the body is flattened its properties
+// become top-level arguments, and the returned map records which body
+// property each of those arguments maps to.
+func (g *MCPGenerator) generateInputSchema(op *parser.Operation) (json.RawMessage, map[string]string) {
 	schema := map[string]interface{}{
 		"type": "object",
 		"properties": make(map[string]interface{}),
@@ -92,14 +242,38 @@ func (g *MCPGenerator) generateInputSchema(op *parser.Operation) json.RawMessage
 		}
 	}
 
-	if op.RequestBody != nil && op.RequestBody.Content != nil {
-		for contentType, mediaType := range op.RequestBody.Content {
-			if strings.Contains(contentType, "json") && mediaType.Schema != nil {
-				properties["body"] = g.convertSchema(mediaType.Schema)
-				if op.RequestBody.Required {
-					required = append(required, "body")
+	var bodyFields map[string]string
+	if _, mediaType, ok := jsonRequestBody(op); ok {
+		bodySchema := g.convertSchema(mediaType.Schema)
+		bodyProperties := flattenableProperties(bodySchema)
+
+		if g.config.flattenBody(op.OperationID) && bodyProperties != nil {
+			bodyFields = make(map[string]string)
+			bodyRequired := map[string]bool{}
+			if names, ok := bodySchema.(map[string]interface{})["required"].([]string); ok {
+				for _, name := range names {
+					bodyRequired[name] = true
+				}
+			}
+
+			names := make([]string, 0, len(bodyProperties))
+			for name := range bodyProperties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				argName := bodyArgumentName(properties, name)
+				properties[argName] = bodyProperties[name]
+				bodyFields[argName] = name
+				if op.RequestBody.Required && bodyRequired[name] {
+					required = append(required, argName)
 				}
-				break
+			}
+		} else {
+			properties["body"] = bodySchema
+			if op.RequestBody.Required {
+				required = append(required, "body")
 			}
 		}
 	}
@@ -111,6 +285,154 @@ func (g *MCPGenerator) generateInputSchema(op *parser.Operation) json.RawMessage
 	}
 
 	data, _ := json.Marshal(schema)
+	return json.RawMessage(data), bodyFields
+}
+
+// bodyArgumentName picks the top-level argument name for a flattened body
+// property, prefixing it with "body_" when it collides with a parameter.
+func bodyArgumentName(properties map[string]interface{}, name string) string {
+	if _, taken := properties[name]; !taken && name != "body" {
+		return name
+	}
+
+	argName := "body_" + name
+	for i := 2; ; i++ {
+		if _, taken := properties[argName]; !taken {
+			return argName
+		}
+		argName = fmt.Sprintf("body_%s_%d", name, i)
+	}
+}
+
+// generateOutputSchema describes the structured content of a tool's result
+// using the JSON schema of the operation's first successful response, when
+// that response is an object. Operations with a successful response that has
+// no JSON body, such as 204 No Content, get no output schema.
+func (g *MCPGenerator) generateOutputSchema(op *parser.Operation) json.RawMessage {
+	for code, response := range op.Responses {
+		if _, _, ok := jsonMediaType(response.Content); strings.HasPrefix(code, "2") && !ok {
+			return nil
+		}
+	}
+
+	_, mediaType, ok := successJSONResponse(op.Responses)
+	if !ok {
+		return nil
+	}
+
+	schema, ok := g.convertSchema(mediaType.Schema).(map[string]interface{})
+	if !ok || schema["type"] != "object" {
+		return nil
+	}
+
+	data, _ := json.Marshal(schema)
+	return json.RawMessage(data)
+}
+
+// successJSONResponse returns the JSON content of an operation's first
+// successful response.
+func successJSONResponse(responses map[string]parser.Response) (string, parser.MediaType, bool) {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	if len(codes) == 0 {
+		return "", parser.MediaType{}, false
+	}
+
+	return jsonMediaType(responses[codes[0]].Content)
+}
+
+func generateAnnotations(method string) *ToolAnnotations {
+	yes := true
+	switch method {
+	case "get", "head", "options":
+		return &ToolAnnotations{ReadOnlyHint: &yes}
+	case "put":
+		return &ToolAnnotations{IdempotentHint: &yes}
+	case "delete":
+		return &ToolAnnotations{DestructiveHint: &yes, IdempotentHint: &yes}
+	}
+	return nil
+}
+
+// flattenableProperties returns the properties of a converted body schema if
+// it is an object that can be hoisted into top-level arguments.
+func flattenableProperties(schema interface{}) map[string]interface{} {
+	m, ok := schema.(map[string]interface{})
+	if !ok || m["type"] != "object" {
+		return nil
+	}
+	properties, _ := m["properties"].(map[string]interface{})
+	if len(properties) == 0 {
+		return nil
+	}
+	return properties
+}
+
+// jsonRequestBody returns the first JSON media type, by content type, of an
+// operation's request body.
+func jsonRequestBody(op *parser.Operation) (string, parser.MediaType, bool) {
+	if op.RequestBody == nil {
+		return "", parser.MediaType{}, false
+	}
+	return jsonMediaType(op.RequestBody.Content)
+}
+
+func jsonMediaType(content map[string]parser.MediaType) (string, parser.MediaType, bool) {
+	contentTypes := make([]string, 0, len(content))
+	for contentType := range content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+
+	for _, contentType := range contentTypes {
+		mediaType := content[contentType]
+		if strings.Contains(contentType, "json") && streamFormat(contentType) == "" && mediaType.Schema != nil {
+			return contentType, mediaType, true
+		}
+	}
+	return "", parser.MediaType{}, false
+}
+
+// hideArguments removes the named arguments from an input schema so they are
+// not advertised to clients.
+func hideArguments(schema json.RawMessage, names map[string]interface{}) json.RawMessage {
+	if len(names) == 0 {
+		return schema
+	}
+
+	var copied map[string]interface{}
+	if err := json.Unmarshal(schema, &copied); err != nil {
+		return schema
+	}
+
+	if properties, ok := copied["properties"].(map[string]interface{}); ok {
+		for name := range names {
+			delete(properties, name)
+		}
+	}
+
+	if required, ok := copied["required"].([]interface{}); ok {
+		kept := []interface{}{}
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, hidden := names[name]; !hidden {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) > 0 {
+			copied["required"] = kept
+		} else {
+			delete(copied, "required")
+		}
+	}
+
+	data, _ := json.Marshal(copied)
 	return json.RawMessage(data)
 }
 
@@ -148,6 +470,60 @@ func (g *MCPGenerator) convertSchema(schema *parser.Schema) interface{} {
 		result["enum"] = schema.Enum
 	}
 
+	if schema.Default != nil {
+		result["default"] = schema.Default
+	}
+
+	if schema.Nullable {
+		result["nullable"] = true
+	}
+
+	if schema.Minimum != nil {
+		if schema.ExclusiveMinimum.Exclusive {
+			result["exclusiveMinimum"] = *schema.Minimum
+		} else {
+			result["minimum"] = *schema.Minimum
+		}
+	}
+	if schema.ExclusiveMinimum.Value != nil {
+		result["exclusiveMinimum"] = *schema.ExclusiveMinimum.Value
+	}
+
+	if schema.Maximum != nil {
+		if schema.ExclusiveMaximum.Exclusive {
+			result["exclusiveMaximum"] = *schema.Maximum
+		} else {
+			result["maximum"] = *schema.Maximum
+		}
+	}
+	if schema.ExclusiveMaximum.Value != nil {
+		result["exclusiveMaximum"] = *schema.ExclusiveMaximum.Value
+	}
+
+	if schema.MinLength != nil {
+		result["minLength"] = *schema.MinLength
+	}
+
+	if schema.MaxLength != nil {
+		result["maxLength"] = *schema.MaxLength
+	}
+
+	if schema.Pattern != "" {
+		result["pattern"] = schema.Pattern
+	}
+
+	if schema.MinItems != nil {
+		result["minItems"] = *schema.MinItems
+	}
+
+	if schema.MaxItems != nil {
+		result["maxItems"] = *schema.MaxItems
+	}
+
+	if schema.UniqueItems {
+		result["uniqueItems"] = true
+	}
+
 	if schema.Type == "object" && len(schema.Properties) > 0 {
 		properties := make(map[string]interface{})
 		for name, propSchema := range schema.Properties {
@@ -171,74 +547,325 @@ func (g *MCPGenerator) GetTools() []MCPTool {
 	return g.tools
 }
 
-func (g *MCPGenerator) ExecuteTool(name string, arguments json.RawMessage) (*CallToolResult, error) {
-	var path string
-	var method string
-	var operation *parser.Operation
+func (g *MCPGenerator) ExecuteTool(ctx context.Context, name string, arguments json.RawMessage) (*CallToolResult, error) {
+	ref, ok := g.operations[name]
+	if !ok {
+		return nil, fmt.Errorf("tool not found: %s", name)
+	}
 
-	for p, pathItem := range g.spec.Paths {
-		operations := pathItem.GetOperations()
-		for m, op := range operations {
-			if op.OperationID == name {
-				path = p
-				method = m
-				operation = op
-				break
-			}
+	args := map[string]interface{}{}
+	if len(arguments) > 0 && string(arguments) != "null" {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	meta, errs := g.prepareArguments(ref, args, g.config.CoerceArguments)
+	if len(errs) > 0 {
+		return &CallToolResult{
+			Content: []ToolContent{
+				{
+					Type: "text",
+					Text: formatValidationErrors(errs),
+				},
+			},
+			IsError: true,
+			Meta:    meta,
+		}, nil
+	}
+
+	continuation, _ := args[continuationArgument].(string)
+	delete(args, continuationArgument)
+
+	req, err := g.newRequest(ctx, ref, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.pagination != nil {
+		result, pages, err := g.collectPages(ctx, ref.pagination, req, continuation)
+		if err != nil {
+			return nil, err
 		}
-		if operation != nil {
-			break
+		if pages != nil {
+			meta = withMeta(meta, "specmill/pagination", pages)
 		}
+		result.Meta = meta
+		return result, nil
 	}
 
-	if operation == nil {
-		return nil, fmt.Errorf("tool not found: %s", name)
+	resp, err := g.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 300 && streamFormat(resp.Header.Get("Content-Type")) != "" {
+		result, stream, err := g.readStream(ctx, name, ref.streaming, resp)
+		if err != nil {
+			return nil, err
+		}
+		requireStructured(ctx, ref, result)
+		result.Meta = withMeta(meta, "specmill/stream", stream)
+		return result, nil
+	}
+
+	data, err := readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newResponseResult(resp.StatusCode, data)
+	if resp.StatusCode == http.StatusAccepted && ref.polling != nil {
+		var polling map[string]any
+		result, polling, err = g.awaitOperation(ctx, ref.polling, resp, data)
+		if err != nil {
+			return nil, err
+		}
+		if polling != nil {
+			meta = withMeta(meta, "specmill/polling", polling)
+		}
+	}
+	requireStructured(ctx, ref, result)
+	result.Meta = meta
+	return result, nil
+}
+
+type outputSchemasKey struct{}
+
+// WithoutOutputSchemas returns a context for clients that are not sent tool
+// output schemas, under which ExecuteTool accepts successful results without
+// structured content.
+func WithoutOutputSchemas(ctx context.Context) context.Context {
+	return context.WithValue(ctx, outputSchemasKey{}, true)
+}
+
+// requireStructured turns a successful result without structured content
+// into an error when the tool advertises an output schema, since clients
+// expect every successful result to conform to it.
+func requireStructured(ctx context.Context, ref *operationRef, result *CallToolResult) {
+	if !ref.structured || result.IsError || result.StructuredContent != nil {
+		return
+	}
+	if without, _ := ctx.Value(outputSchemasKey{}).(bool); without {
+		return
+	}
+	result.IsError = true
+	result.Content = append([]ToolContent{{
+		Type: "text",
+		Text: "Upstream response does not match the output schema: expected a JSON object",
+	}}, result.Content...)
+}
+
+// withMeta adds an entry to result metadata, creating the map if needed.
+func withMeta(meta map[string]any, key string, value any) map[string]any {
+	if meta == nil {
+		meta = map[string]any{}
+	}
+	meta[key] = value
+	return meta
+}
+
+// prepareArguments coerces arguments when asked to, applies schema defaults
+// and pinned values, and validates the result. It returns the metadata
+// describing what was changed along with any validation errors.
+func (g *MCPGenerator) prepareArguments(ref *operationRef, args map[string]interface{}, coerce bool) (map[string]any, []ValidationError) {
+	meta := map[string]any{}
+	if coerce {
+		if coercions := coerceArguments(ref.inputSchema, args); len(coercions) > 0 {
+			meta["specmill/coercions"] = coercions
+		}
+	}
+
+	if defaults := applyDefaults(ref.inputSchema, args, "$"); len(defaults) > 0 {
+		meta["specmill/defaults"] = defaults
 	}
 
-	var args map[string]interface{}
-	if err := json.Unmarshal(arguments, &args); err != nil {
-		return nil, fmt.Errorf("invalid arguments: %w", err)
+	for name, value := range ref.pinned {
+		args[name] = value
 	}
 
-	url := g.baseURL + path
+	if len(meta) == 0 {
+		meta = nil
+	}
+
+	return meta, validateArguments(ref.inputSchema, args)
+}
+
+// newRequest builds the upstream request for an operation from validated
+// arguments.
+func (g *MCPGenerator) newRequest(ctx context.Context, ref *operationRef, args map[string]interface{}) (*http.Request, error) {
+	path, method, operation := ref.path, ref.method, ref.operation
+
+	endpoint := g.baseURL + path
 	for _, param := range operation.Parameters {
 		if param.In == "path" {
 			if value, ok := args[param.Name]; ok {
 				placeholder := fmt.Sprintf("{%s}", param.Name)
-				url = strings.Replace(url, placeholder, fmt.Sprint(value), 1)
+				endpoint = strings.Replace(endpoint, placeholder, url.PathEscape(formatArgument(value)), 1)
 				delete(args, param.Name)
 			}
 		}
 	}
 
-	req, err := http.NewRequest(strings.ToUpper(method), url, nil)
+	if ref.bodyFields != nil {
+		assembled := make(map[string]interface{})
+		for argName, propName := range ref.bodyFields {
+			if value, ok := args[argName]; ok {
+				assembled[propName] = value
+				delete(args, argName)
+			}
+		}
+		if len(assembled) > 0 || operation.RequestBody.Required {
+			args["body"] = assembled
+		}
+	}
+
+	var body io.Reader
+	contentType, _, hasBody := jsonRequestBody(operation)
+	if value, ok := args["body"]; ok && hasBody {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), endpoint, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	for _, param := range operation.Parameters {
+		if param.In == "header" {
+			if value, ok := args["header_"+param.Name]; ok {
+				req.Header.Set(param.Name, formatArgument(value))
+			}
+		}
+	}
+
 	q := req.URL.Query()
 	for _, param := range operation.Parameters {
 		if param.In == "query" {
 			if value, ok := args[param.Name]; ok {
-				q.Add(param.Name, fmt.Sprint(value))
+				if items, ok := value.([]interface{}); ok {
+					for _, item := range items {
+						q.Add(param.Name, formatArgument(item))
+					}
+				} else {
+					q.Add(param.Name, formatArgument(value))
+				}
 				delete(args, param.Name)
 			}
 		}
 	}
 	req.URL.RawQuery = q.Encode()
 
+	if g.requestEditor != nil {
+		if err := g.requestEditor(req); err != nil {
+			return nil, fmt.Errorf("failed to prepare request: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+// formatArgument renders an argument value for a path, query or header
+// parameter. Numbers are written in plain decimal, since validated and
+// coerced arguments are float64 and would otherwise use exponent form from
+// 1e6 up. Arrays are comma-separated and objects are JSON-encoded.
+func formatArgument(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = formatArgument(item)
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// send performs an upstream request and reads at most maxResponseBytes of
+// its body. Transfers are reported to the progress tracker of the request's
+// context, if any.
+func (g *MCPGenerator) send(req *http.Request) (*http.Response, []byte, error) {
+	resp, err := g.do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := readResponse(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, data, nil
+}
+
+// do performs an upstream request without reading the response body.
+func (g *MCPGenerator) do(req *http.Request) (*http.Response, error) {
+	if tracker := progressFromContext(req.Context()); tracker != nil && req.Body != nil {
+		req.Body = newProgressReader(req.Body, tracker, "Uploaded", req.ContentLength)
+	}
+
 	resp, err := g.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
+	return resp, nil
+}
+
+// readResponse reads and closes at most maxResponseBytes of a response body.
+func readResponse(resp *http.Response) ([]byte, error) {
 	defer resp.Body.Close()
 
-	return &CallToolResult{
-		Content: []ToolContent{
-			{
-				Type: "text",
-				Text: fmt.Sprintf("HTTP %d response received", resp.StatusCode),
-			},
-		},
-	}, nil
+	var body io.Reader = resp.Body
+	if tracker := progressFromContext(resp.Request.Context()); tracker != nil {
+		body = newProgressReader(resp.Body, tracker, "Downloaded", resp.ContentLength)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, nil
+}
+
+// newResponseResult converts an upstream response into a tool result. The
+// body is returned as text; JSON object bodies of successful responses are
+// also returned as structured content. Error statuses set isError.
+func newResponseResult(status int, data []byte) *CallToolResult {
+	text := string(data)
+	if len(bytes.TrimSpace(data)) == 0 {
+		text = fmt.Sprintf("HTTP %d response received", status)
+	}
+
+	result := &CallToolResult{}
+	if status >= 400 {
+		result.IsError = true
+		text = fmt.Sprintf("HTTP %d: %s", status, text)
+	} else {
+		var structured map[string]any
+		if err := json.Unmarshal(data, &structured); err == nil {
+			result.StructuredContent = structured
+		}
+	}
+
+	result.Content = []ToolContent{{Type: "text", Text: text}}
+	return result
 }
\ No newline at end of file
@@ -1,7 +1,13 @@
 package generator
 
 import (
+	"context"
 	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	"specmill/parser"
@@ -299,4 +305,548 @@ func TestGenerateDescription(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestGenerateToolsOrder(t *testing.T) {
+	spec := &parser.OpenAPISpec{
+		Paths: map[string]parser.PathItem{
+			"/users": {
+				Post: &parser.Operation{OperationID: "createUser"},
+				Get:  &parser.Operation{OperationID: "listUsers"},
+			},
+			"/pets/{petId}": {
+				Delete: &parser.Operation{OperationID: "deletePet"},
+				Get:    &parser.Operation{OperationID: "getPet"},
+			},
+			"/pets": {
+				Get: &parser.Operation{OperationID: "listPets"},
+			},
+		},
+	}
+
+	expected := []string{"listPets", "getPet", "deletePet", "listUsers", "createUser"}
+
+	for run := 0; run < 5; run++ {
+		gen := NewMCPGenerator(spec)
+		if err := gen.GenerateTools(); err != nil {
+			t.Fatalf("Failed to generate tools: %v", err)
+		}
+
+		tools := gen.GetTools()
+		if len(tools) != len(expected) {
+			t.Fatalf("Expected %d tools, got: %d", len(expected), len(tools))
+		}
+		for i, name := range expected {
+			if tools[i].Name != name {
+				t.Errorf("Run %d: expected tool %d to be '%s', got: '%s'", run, i, name, tools[i].Name)
+			}
+		}
+
+		ref, ok := gen.operations["listPets"]
+		if !ok {
+			t.Fatal("listPets should be indexed")
+		}
+		if ref.path != "/pets" || ref.method != "get" {
+			t.Errorf("Expected listPets to resolve to GET /pets, got: %s %s", ref.method, ref.path)
+		}
+	}
+}
+
+func TestGenerateToolsDuplicateOperationID(t *testing.T) {
+	spec := &parser.OpenAPISpec{
+		Paths: map[string]parser.PathItem{
+			"/pets": {
+				Get: &parser.Operation{OperationID: "listPets"},
+			},
+			"/orders": {
+				Get: &parser.Operation{OperationID: "listPets"},
+			},
+		},
+	}
+
+	gen := NewMCPGenerator(spec)
+	err := gen.GenerateTools()
+	if err == nil || !strings.Contains(err.Error(), "GET /orders and GET /pets") {
+		t.Errorf("Expected duplicate operationId error, got: %v", err)
+	}
+
+	gen = NewMCPGenerator(spec)
+	gen.SetOperationFilter(func(method, path string, op *parser.Operation) bool {
+		return path == "/pets"
+	})
+	if err := gen.GenerateTools(); err != nil {
+		t.Errorf("Expected filtered duplicate to be ignored, got: %v", err)
+	}
+}
+
+func TestGenerateToolsTwice(t *testing.T) {
+	spec := &parser.OpenAPISpec{
+		Paths: map[string]parser.PathItem{
+			"/pets": {
+				Get: &parser.Operation{OperationID: "listPets"},
+			},
+		},
+	}
+
+	gen := NewMCPGenerator(spec)
+	for i := 0; i < 2; i++ {
+		if err := gen.GenerateTools(); err != nil {
+			t.Fatalf("Failed to generate tools: %v", err)
+		}
+	}
+
+	if len(gen.GetTools()) != 1 {
+		t.Errorf("Expected 1 tool after regenerating, got: %d", len(gen.GetTools()))
+	}
+}
+
+func TestExecuteToolNotFound(t *testing.T) {
+	gen := NewMCPGenerator(&parser.OpenAPISpec{})
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	_, err := gen.ExecuteTool(context.Background(), "missing", json.RawMessage(`{}`))
+	if err == nil {
+		t.Fatal("Expected error for unknown tool")
+	}
+}
+
+func TestFlattenBody(t *testing.T) {
+	var receivedBody map[string]interface{}
+	var receivedQuery string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedQuery = r.URL.RawQuery
+		receivedBody = nil
+		json.NewDecoder(r.Body).Decode(&receivedBody)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	bodyOperation := func(id string) *parser.Operation {
+		return &parser.Operation{
+			OperationID: id,
+			Parameters: []parser.Parameter{
+				{Name: "name", In: "query", Schema: &parser.Schema{Type: "string"}},
+			},
+			RequestBody: &parser.RequestBody{
+				Required: true,
+				Content: map[string]parser.MediaType{
+					"application/json": {
+						Schema: &parser.Schema{
+							Type: "object",
+							Properties: map[string]*parser.Schema{
+								"name":   {Type: "string"},
+								"body":   {Type: "string"},
+								"status": {Type: "string"},
+							},
+							Required: []string{"name"},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	spec := &parser.OpenAPISpec{
+		Servers: []parser.Server{{URL: upstream.URL}},
+		Paths: map[string]parser.PathItem{
+			"/pets": {
+				Post: bodyOperation("createPet"),
+				Put:  bodyOperation("updatePet"),
+			},
+		},
+	}
+
+	disabled := false
+	cfg := &Config{
+		FlattenBody: true,
+		Operations: map[string]OperationConfig{
+			"updatePet": {FlattenBody: &disabled},
+		},
+	}
+
+	gen := NewMCPGeneratorWithConfig(spec, cfg)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	schemas := map[string]map[string]interface{}{}
+	for _, tool := range gen.GetTools() {
+		var schema map[string]interface{}
+		if err := json.Unmarshal(tool.InputSchema, &schema); err != nil {
+			t.Fatalf("Failed to unmarshal schema: %v", err)
+		}
+		schemas[tool.Name] = schema
+	}
+
+	props := schemas["createPet"]["properties"].(map[string]interface{})
+	for _, name := range []string{"name", "body_name", "body_body", "status"} {
+		if _, ok := props[name]; !ok {
+			t.Errorf("Expected flattened argument '%s', got: %v", name, props)
+		}
+	}
+	if _, ok := props["body"]; ok {
+		t.Error("Flattened schema should not have a 'body' argument")
+	}
+	required := schemas["createPet"]["required"].([]interface{})
+	if len(required) != 1 || required[0] != "body_name" {
+		t.Errorf("Expected 'body_name' to be required, got: %v", required)
+	}
+
+	props = schemas["updatePet"]["properties"].(map[string]interface{})
+	if _, ok := props["body"]; !ok {
+		t.Error("Per-operation override should keep the nested 'body' argument")
+	}
+
+	result, err := gen.ExecuteTool(context.Background(), "createPet", json.RawMessage(`{"name": "q", "body_name": "Rex", "body_body": "text", "status": "sold"}`))
+	if err != nil {
+		t.Fatalf("Failed to execute tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected call to succeed, got: %s", result.Content[0].Text)
+	}
+	if receivedQuery != "name=q" {
+		t.Errorf("Expected query 'name=q', got: %s", receivedQuery)
+	}
+	expected := map[string]interface{}{"name": "Rex", "body": "text", "status": "sold"}
+	if !reflect.DeepEqual(receivedBody, expected) {
+		t.Errorf("Expected reassembled body %v, got: %v", expected, receivedBody)
+	}
+}
+
+func TestGenerateToolMetadata(t *testing.T) {
+	spec := &parser.OpenAPISpec{
+		Paths: map[string]parser.PathItem{
+			"/pets/{petId}": {
+				Get: &parser.Operation{
+					OperationID: "getPet",
+					Summary:     "Get a pet",
+					Responses: map[string]parser.Response{
+						"404": {Description: "Not found"},
+						"200": {
+							Content: map[string]parser.MediaType{
+								"application/json": {
+									Schema: &parser.Schema{
+										Type:       "object",
+										Properties: map[string]*parser.Schema{"name": {Type: "string"}},
+									},
+								},
+							},
+						},
+					},
+				},
+				Delete: &parser.Operation{
+					OperationID: "deletePet",
+					Responses: map[string]parser.Response{
+						"200": {
+							Content: map[string]parser.MediaType{
+								"application/json": {
+									Schema: &parser.Schema{Type: "array", Items: &parser.Schema{Type: "string"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	gen := NewMCPGenerator(spec)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	tools := gen.GetTools()
+	getPet, deletePet := tools[0], tools[1]
+
+	if getPet.Title != "Get a pet" {
+		t.Errorf("Expected title from summary, got: %s", getPet.Title)
+	}
+	var outputSchema map[string]interface{}
+	if err := json.Unmarshal(getPet.OutputSchema, &outputSchema); err != nil {
+		t.Fatalf("Expected output schema, got: %s", getPet.OutputSchema)
+	}
+	if outputSchema["type"] != "object" {
+		t.Errorf("Expected object output schema, got: %v", outputSchema)
+	}
+	if getPet.Annotations == nil || getPet.Annotations.ReadOnlyHint == nil || !*getPet.Annotations.ReadOnlyHint {
+		t.Error("GET operations should be annotated read-only")
+	}
+
+	if deletePet.OutputSchema != nil {
+		t.Errorf("Non-object responses should not produce an output schema, got: %s", deletePet.OutputSchema)
+	}
+	if deletePet.Annotations == nil || deletePet.Annotations.DestructiveHint == nil || !*deletePet.Annotations.DestructiveHint {
+		t.Error("DELETE operations should be annotated destructive")
+	}
+}
+
+func TestExecuteToolOutputSchema(t *testing.T) {
+	var body string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer upstream.Close()
+
+	petSchema := &parser.Schema{
+		Type:       "object",
+		Properties: map[string]*parser.Schema{"name": {Type: "string"}},
+	}
+	spec := &parser.OpenAPISpec{
+		Servers: []parser.Server{{URL: upstream.URL}},
+		Paths: map[string]parser.PathItem{
+			"/pets/{petId}": {
+				Get: &parser.Operation{
+					OperationID: "getPet",
+					Parameters: []parser.Parameter{
+						{Name: "petId", In: "path", Required: true, Schema: &parser.Schema{Type: "string"}},
+					},
+					Responses: map[string]parser.Response{
+						"200": {Content: map[string]parser.MediaType{"application/json": {Schema: petSchema}}},
+					},
+				},
+				Put: &parser.Operation{
+					OperationID: "updatePet",
+					Parameters: []parser.Parameter{
+						{Name: "petId", In: "path", Required: true, Schema: &parser.Schema{Type: "string"}},
+					},
+					Responses: map[string]parser.Response{
+						"200": {Content: map[string]parser.MediaType{"application/json": {Schema: petSchema}}},
+						"204": {Description: "Unchanged"},
+					},
+				},
+			},
+		},
+	}
+
+	gen := NewMCPGenerator(spec)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	tools := gen.GetTools()
+	if tools[0].Name != "getPet" || tools[0].OutputSchema == nil {
+		t.Fatalf("Expected getPet to advertise an output schema, got: %+v", tools[0])
+	}
+	if tools[1].Name != "updatePet" || tools[1].OutputSchema != nil {
+		t.Errorf("Operations that may answer without a body should not advertise an output schema, got: %s", tools[1].OutputSchema)
+	}
+
+	tests := []struct {
+		name    string
+		tool    string
+		body    string
+		isError bool
+	}{
+		{name: "Object body", tool: "getPet", body: `{"name": "Rex"}`},
+		{name: "Empty body", tool: "getPet", body: "", isError: true},
+		{name: "Array body", tool: "getPet", body: `["Rex"]`, isError: true},
+		{name: "No output schema", tool: "updatePet", body: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body = tt.body
+			result, err := gen.ExecuteTool(context.Background(), tt.tool, json.RawMessage(`{"petId": "1"}`))
+			if err != nil {
+				t.Fatalf("Failed to execute tool: %v", err)
+			}
+			if result.IsError != tt.isError {
+				t.Errorf("Expected isError %v, got: %+v", tt.isError, result)
+			}
+			if !result.IsError && tt.tool == "getPet" && result.StructuredContent == nil {
+				t.Errorf("Expected structured content, got: %+v", result)
+			}
+		})
+	}
+}
+
+func TestNewResponseResult(t *testing.T) {
+	result := newResponseResult(http.StatusOK, []byte(`{"name": "Rex"}`))
+	if result.IsError || result.StructuredContent["name"] != "Rex" {
+		t.Errorf("Expected structured content for JSON object, got: %+v", result)
+	}
+
+	result = newResponseResult(http.StatusOK, []byte(`["a"]`))
+	if result.StructuredContent != nil || result.Content[0].Text != `["a"]` {
+		t.Errorf("Expected text-only result for JSON array, got: %+v", result)
+	}
+
+	result = newResponseResult(http.StatusNoContent, nil)
+	if result.Content[0].Text != "HTTP 204 response received" {
+		t.Errorf("Expected placeholder text for empty body, got: %s", result.Content[0].Text)
+	}
+
+	result = newResponseResult(http.StatusNotFound, []byte(`{"message": "Pet not found"}`))
+	if !result.IsError || result.StructuredContent != nil {
+		t.Errorf("Expected error result without structured content, got: %+v", result)
+	}
+	if result.Content[0].Text != `HTTP 404: {"message": "Pet not found"}` {
+		t.Errorf("Unexpected error text: %s", result.Content[0].Text)
+	}
+}
+
+func TestExecuteToolRequestBodyAndHeaders(t *testing.T) {
+	var received *http.Request
+	var receivedBody []byte
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer upstream.Close()
+
+	spec := &parser.OpenAPISpec{
+		Servers: []parser.Server{{URL: upstream.URL}},
+		Paths: map[string]parser.PathItem{
+			"/pets": {
+				Post: &parser.Operation{
+					OperationID: "createPet",
+					Parameters: []parser.Parameter{
+						{Name: "X-Request-Id", In: "header", Schema: &parser.Schema{Type: "string"}},
+					},
+					RequestBody: &parser.RequestBody{
+						Content: map[string]parser.MediaType{
+							"text/plain":                   {Schema: &parser.Schema{Type: "string"}},
+							"application/merge-patch+json": {Schema: &parser.Schema{Type: "object"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	gen := NewMCPGenerator(spec)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		args        string
+		body        string
+		contentType string
+		requestID   string
+	}{
+		{name: "Body and header", args: `{"header_X-Request-Id": "abc", "body": {"name": "Rex"}}`, body: `{"name":"Rex"}`, contentType: "application/merge-patch+json", requestID: "abc"},
+		{name: "No body", args: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := gen.ExecuteTool(context.Background(), "createPet", json.RawMessage(tt.args))
+			if err != nil {
+				t.Fatalf("Failed to execute tool: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("Expected call to succeed, got: %s", result.Content[0].Text)
+			}
+
+			if string(receivedBody) != tt.body {
+				t.Errorf("Expected body %q, got: %q", tt.body, receivedBody)
+			}
+			if received.Header.Get("Content-Type") != tt.contentType {
+				t.Errorf("Expected content type %q, got: %q", tt.contentType, received.Header.Get("Content-Type"))
+			}
+			if received.Header.Get("X-Request-Id") != tt.requestID {
+				t.Errorf("Expected request id header %q, got: %q", tt.requestID, received.Header.Get("X-Request-Id"))
+			}
+		})
+	}
+}
+
+func TestExecuteToolEscapesPathParameters(t *testing.T) {
+	var requested string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+	}))
+	defer upstream.Close()
+
+	spec := &parser.OpenAPISpec{
+		Servers: []parser.Server{{URL: upstream.URL}},
+		Paths: map[string]parser.PathItem{
+			"/files/{name}": {
+				Get: &parser.Operation{
+					OperationID: "getFile",
+					Parameters: []parser.Parameter{
+						{Name: "name", In: "path", Required: true, Schema: &parser.Schema{Type: "string"}},
+						{Name: "version", In: "query", Schema: &parser.Schema{Type: "string"}},
+					},
+				},
+			},
+		},
+	}
+
+	gen := NewMCPGenerator(spec)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	tests := []struct {
+		args      string
+		requested string
+	}{
+		{args: `{"name": "report.txt"}`, requested: "/files/report.txt"},
+		{args: `{"name": "../admin/secrets"}`, requested: "/files/..%2Fadmin%2Fsecrets"},
+		{args: `{"name": "a/b?x=1", "version": "2"}`, requested: "/files/a%2Fb%3Fx=1?version=2"},
+	}
+
+	for _, tt := range tests {
+		if _, err := gen.ExecuteTool(context.Background(), "getFile", json.RawMessage(tt.args)); err != nil {
+			t.Fatalf("Failed to execute tool: %v", err)
+		}
+		if requested != tt.requested {
+			t.Errorf("Expected upstream request %s for %s, got: %s", tt.requested, tt.args, requested)
+		}
+	}
+}
+
+func TestExecuteToolFormatsArguments(t *testing.T) {
+	var received *http.Request
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+	}))
+	defer upstream.Close()
+
+	spec := &parser.OpenAPISpec{
+		Servers: []parser.Server{{URL: upstream.URL}},
+		Paths: map[string]parser.PathItem{
+			"/pets/{petId}": {
+				Get: &parser.Operation{
+					OperationID: "getPet",
+					Parameters: []parser.Parameter{
+						{Name: "petId", In: "path", Required: true, Schema: &parser.Schema{Type: "integer"}},
+						{Name: "limit", In: "query", Schema: &parser.Schema{Type: "integer"}},
+						{Name: "tags", In: "query", Schema: &parser.Schema{Type: "array", Items: &parser.Schema{Type: "string"}}},
+						{Name: "X-Weight", In: "header", Schema: &parser.Schema{Type: "number"}},
+						{Name: "X-Ids", In: "header", Schema: &parser.Schema{Type: "array", Items: &parser.Schema{Type: "integer"}}},
+					},
+				},
+			},
+		},
+	}
+
+	gen := NewMCPGenerator(spec)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	args := `{"petId": 5555555, "limit": 2000000, "tags": ["a", "b"], "header_X-Weight": 12500000.5, "header_X-Ids": [1000000, 2]}`
+	result, err := gen.ExecuteTool(context.Background(), "getPet", json.RawMessage(args))
+	if err != nil {
+		t.Fatalf("Failed to execute tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected call to succeed, got: %s", result.Content[0].Text)
+	}
+
+	if uri := received.URL.RequestURI(); uri != "/pets/5555555?limit=2000000&tags=a&tags=b" {
+		t.Errorf("Unexpected upstream request: %s", uri)
+	}
+	if weight := received.Header.Get("X-Weight"); weight != "12500000.5" {
+		t.Errorf("Expected X-Weight 12500000.5, got: %s", weight)
+	}
+	if ids := received.Header.Get("X-Ids"); ids != "1000000,2" {
+		t.Errorf("Expected X-Ids 1000000,2, got: %s", ids)
+	}
+}
@@ -0,0 +1,441 @@
+package generator
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"specmill/parser"
+)
+
+const (
+	defaultPaginationMaxPages = 5
+	defaultPaginationMaxItems = 500
+
+	// continuationArgument is added to the input schema of paginated tools
+	// to resume collecting where a previous call stopped.
+	continuationArgument = "continuationToken"
+)
+
+// defaultItemFields are the properties searched for a page's items when no
+// items path is configured and the page is not an array.
+var defaultItemFields = []string{"items", "data", "results", "records", "values"}
+
+// paginationRule is a validated PaginationConfig with defaults applied.
+type paginationRule struct {
+	strategy      string
+	items         jsonPath
+	nextCursor    jsonPath
+	cursorParam   string
+	offsetParam   string
+	limitParam    string
+	pageParam     string
+	pageSizeParam string
+	firstPage     int
+	maxPages      int
+	maxItems      int
+}
+
+// paginationRule returns the pagination rule of an operation, taken from
+// the config, else its x-specmill-pagination extension, else the pagination
+// entry of its x-specmill extension, or nil when pages are not followed.
+func (g *MCPGenerator) paginationRule(op *parser.Operation) (*paginationRule, error) {
+	cfg := g.config.Operations[op.OperationID].Pagination
+	if cfg == nil && len(op.SpecmillPagination) > 0 {
+		extension, err := operationExtension(map[string]interface{}{"pagination": op.SpecmillPagination})
+		if err != nil {
+			return nil, fmt.Errorf("invalid x-specmill-pagination extension for %s: %w", op.OperationID, err)
+		}
+		cfg = extension.Pagination
+	}
+	if cfg == nil {
+		extension, err := operationExtension(op.Specmill)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x-specmill extension for %s: %w", op.OperationID, err)
+		}
+		cfg = extension.Pagination
+	}
+	if cfg == nil {
+		return nil, nil
+	}
+
+	rule, err := newPaginationRule(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pagination config for %s: %w", op.OperationID, err)
+	}
+	return rule, nil
+}
+
+func newPaginationRule(cfg *PaginationConfig) (*paginationRule, error) {
+	rule := &paginationRule{
+		strategy:      cfg.Strategy,
+		cursorParam:   cfg.CursorParam,
+		offsetParam:   valueOr(cfg.OffsetParam, "offset"),
+		limitParam:    valueOr(cfg.LimitParam, "limit"),
+		pageParam:     valueOr(cfg.PageParam, "page"),
+		pageSizeParam: valueOr(cfg.PageSizeParam, "pageSize"),
+		firstPage:     1,
+		maxPages:      cfg.MaxPages,
+		maxItems:      cfg.MaxItems,
+	}
+	if cfg.FirstPage != nil {
+		rule.firstPage = *cfg.FirstPage
+	}
+	if rule.maxPages <= 0 {
+		rule.maxPages = defaultPaginationMaxPages
+	}
+	if rule.maxItems <= 0 {
+		rule.maxItems = defaultPaginationMaxItems
+	}
+
+	var err error
+	if cfg.Items != "" {
+		if rule.items, err = parseJSONPath(cfg.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	switch cfg.Strategy {
+	case "link", "offset", "page":
+	case "cursor":
+		if cfg.CursorParam == "" || cfg.NextCursor == "" {
+			return nil, fmt.Errorf("cursor strategy requires cursorParam and nextCursor")
+		}
+		if rule.nextCursor, err = parseJSONPath(cfg.NextCursor); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown strategy %q: expected link, cursor, offset or page", cfg.Strategy)
+	}
+	return rule, nil
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func (r *paginationRule) description() string {
+	return fmt.Sprintf("Results are collected from up to %d pages or %d items. If more remain, the result includes a continuationToken; call again with the same arguments and that token to continue.", r.maxPages, r.maxItems)
+}
+
+// addContinuationArgument adds the continuationToken argument to an input
+// schema.
+func addContinuationArgument(schema json.RawMessage) json.RawMessage {
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(schema, &parsed); err != nil {
+		return schema
+	}
+	properties, ok := parsed["properties"].(map[string]interface{})
+	if !ok {
+		properties = map[string]interface{}{}
+		parsed["properties"] = properties
+	}
+	properties[continuationArgument] = map[string]interface{}{
+		"type":        "string",
+		"description": "Token from a previous result to continue collecting pages",
+	}
+
+	data, err := json.Marshal(parsed)
+	if err != nil {
+		return schema
+	}
+	return data
+}
+
+// pageToken is the decoded form of a continuation token: the URL of the next
+// page for the link strategy, and the value of the cursor, offset or page
+// parameter otherwise. Skip counts the items of that page already returned
+// when a call stopped part-way through it; an empty Next with a Skip refers
+// to the first page.
+type pageToken struct {
+	Strategy string `json:"s"`
+	Next     string `json:"n,omitempty"`
+	Skip     int    `json:"k,omitempty"`
+}
+
+func encodePageToken(token pageToken) string {
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePageToken(value string) (pageToken, error) {
+	var token pageToken
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || json.Unmarshal(data, &token) != nil || token.Skip < 0 || (token.Next == "" && token.Skip == 0) {
+		return pageToken{}, fmt.Errorf("invalid %s", continuationArgument)
+	}
+	return token, nil
+}
+
+// collectPages fetches the first page with req and follows the rule's
+// strategy until the upstream has no more pages or the page or item limit
+// is reached, returning the items of every page in a single result. A
+// first page whose items cannot be found is returned as it is.
+func (g *MCPGenerator) collectPages(ctx context.Context, rule *paginationRule, req *http.Request, continuation string) (*CallToolResult, map[string]any, error) {
+	skip := 0
+	if continuation != "" {
+		token, err := decodePageToken(continuation)
+		if err == nil && token.Strategy != rule.strategy {
+			err = fmt.Errorf("invalid %s: issued for a different pagination strategy", continuationArgument)
+		}
+		if err == nil && token.Next != "" {
+			err = rule.apply(req, token.Next)
+		}
+		skip = token.Skip
+		if err != nil {
+			return &CallToolResult{Content: []ToolContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil, nil
+		}
+	}
+
+	tracker := progressFromContext(ctx)
+	items := []interface{}{}
+	pageSize := rule.requestedPageSize(req)
+	var next pageToken
+	failure := ""
+
+	pages := 0
+	for {
+		resp, data, err := g.send(req)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var doc interface{}
+		var page []interface{}
+		ok := resp.StatusCode < 300 && json.Unmarshal(data, &doc) == nil
+		if ok {
+			page, ok = rule.pageItems(doc)
+		}
+		if !ok {
+			if pages == 0 {
+				return newResponseResult(resp.StatusCode, data), nil, nil
+			}
+			// Keep the pages collected so far; next still names the
+			// failed page so that the call can be resumed.
+			failure = fmt.Sprintf("page %d: HTTP %d: %s", pages+1, resp.StatusCode, data)
+			break
+		}
+
+		pages++
+		count := len(page)
+		page = page[min(skip, count):]
+
+		// Stop part-way through a page that exceeds the item limit, resuming
+		// from its first item not returned.
+		if excess := len(items) + len(page) - rule.maxItems; excess > 0 {
+			items = append(items, page[:len(page)-excess]...)
+			next = rule.resume(req, skip+len(page)-excess)
+			tracker.step(fmt.Sprintf("Fetched page %d (%d items)", pages, len(items)))
+			break
+		}
+		skip = 0
+		items = append(items, page...)
+		tracker.step(fmt.Sprintf("Fetched page %d (%d items)", pages, len(items)))
+
+		if pageSize == 0 {
+			pageSize = count
+		}
+		next = pageToken{Next: rule.next(req, resp, doc, count, pageSize)}
+		if next.Next == "" || pages >= rule.maxPages || len(items) >= rule.maxItems {
+			break
+		}
+
+		if req, err = nextPageRequest(ctx, req); err != nil {
+			return nil, nil, err
+		}
+		if err := rule.apply(req, next.Next); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	collected := map[string]any{"items": items}
+	meta := map[string]any{"pages": pages, "items": len(items)}
+	if failure != "" {
+		collected["error"] = failure
+	}
+	if next.Next != "" || next.Skip > 0 {
+		next.Strategy = rule.strategy
+		collected[continuationArgument] = encodePageToken(next)
+		meta["truncated"] = true
+	}
+
+	data, err := json.Marshal(collected)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode collected pages: %w", err)
+	}
+	result := &CallToolResult{
+		Content:           []ToolContent{{Type: "text", Text: string(data)}},
+		StructuredContent: collected,
+	}
+	return result, meta, nil
+}
+
+// pageItems returns the items of a decoded page: the value at the items
+// path, the page itself when it is an array, or the first array found under
+// a conventional property name.
+func (r *paginationRule) pageItems(doc interface{}) ([]interface{}, bool) {
+	if r.items != nil {
+		value, ok := r.items.lookup(doc)
+		if !ok || value == nil {
+			return []interface{}{}, ok
+		}
+		items, ok := value.([]interface{})
+		return items, ok
+	}
+
+	if items, ok := doc.([]interface{}); ok {
+		return items, true
+	}
+	if object, ok := doc.(map[string]interface{}); ok {
+		for _, field := range defaultItemFields {
+			if items, ok := object[field].([]interface{}); ok {
+				return items, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// requestedPageSize returns the page size requested by the limit or
+// pageSize query parameter, or 0 when the upstream default applies.
+func (r *paginationRule) requestedPageSize(req *http.Request) int {
+	param := ""
+	switch r.strategy {
+	case "offset":
+		param = r.limitParam
+	case "page":
+		param = r.pageSizeParam
+	default:
+		return 0
+	}
+	size, _ := strconv.Atoi(req.URL.Query().Get(param))
+	return max(size, 0)
+}
+
+// next returns the token value for the page after the one just fetched, or
+// "" when it was the last page.
+func (r *paginationRule) next(req *http.Request, resp *http.Response, doc interface{}, count, pageSize int) string {
+	switch r.strategy {
+	case "link":
+		if ref := nextLink(resp.Header); ref != "" {
+			if u, err := req.URL.Parse(ref); err == nil {
+				return u.String()
+			}
+		}
+	case "cursor":
+		if value, ok := r.nextCursor.lookup(doc); ok && value != nil {
+			if cursor := formatArgument(value); cursor != "" && cursor != req.URL.Query().Get(r.cursorParam) {
+				return cursor
+			}
+		}
+	case "offset":
+		if count > 0 && count >= pageSize {
+			offset, _ := strconv.Atoi(req.URL.Query().Get(r.offsetParam))
+			return strconv.Itoa(offset + count)
+		}
+	case "page":
+		if count > 0 && count >= pageSize {
+			page, err := strconv.Atoi(req.URL.Query().Get(r.pageParam))
+			if err != nil {
+				page = r.firstPage
+			}
+			return strconv.Itoa(page + 1)
+		}
+	}
+	return ""
+}
+
+// resume returns the token for continuing at the given position within the
+// page fetched by req. The offset strategy can address the item directly;
+// the others refetch the page and skip the items already returned.
+func (r *paginationRule) resume(req *http.Request, position int) pageToken {
+	current := req.URL.Query()
+	switch r.strategy {
+	case "link":
+		return pageToken{Next: req.URL.String(), Skip: position}
+	case "offset":
+		offset, _ := strconv.Atoi(current.Get(r.offsetParam))
+		return pageToken{Next: strconv.Itoa(offset + position)}
+	case "page":
+		return pageToken{Next: current.Get(r.pageParam), Skip: position}
+	}
+	return pageToken{Next: current.Get(r.cursorParam), Skip: position}
+}
+
+// apply points req at the page identified by a token value.
+func (r *paginationRule) apply(req *http.Request, next string) error {
+	if r.strategy == "link" {
+		u, err := req.URL.Parse(next)
+		if err != nil || u.Host != req.URL.Host {
+			return fmt.Errorf("invalid %s: next page is not on the upstream host", continuationArgument)
+		}
+		req.URL = u
+		req.Host = ""
+		return nil
+	}
+
+	param := r.cursorParam
+	switch r.strategy {
+	case "offset":
+		param = r.offsetParam
+	case "page":
+		param = r.pageParam
+	}
+	if r.strategy != "cursor" {
+		if _, err := strconv.Atoi(next); err != nil {
+			return fmt.Errorf("invalid %s", continuationArgument)
+		}
+	}
+
+	q := req.URL.Query()
+	q.Set(param, next)
+	req.URL.RawQuery = q.Encode()
+	return nil
+}
+
+// nextPageRequest copies a request for the following page, including its
+// body.
+func nextPageRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	next := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy request body: %w", err)
+		}
+		next.Body = body
+	}
+	return next, nil
+}
+
+// nextLink returns the target of the rel="next" link in RFC 8288 Link
+// headers.
+func nextLink(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,370 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"specmill/parser"
+)
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		links    []string
+		expected string
+	}{
+		{links: nil, expected: ""},
+		{links: []string{`<https://api.example.com/items?page=2>; rel="next"`}, expected: "https://api.example.com/items?page=2"},
+		{links: []string{`</items?page=1>; rel="prev", </items?page=3>; rel=next`}, expected: "/items?page=3"},
+		{links: []string{`</items?page=1>; rel="first"`, `</items?page=2>; rel="last next"`}, expected: "/items?page=2"},
+		{links: []string{`</items?page=9>; rel="last"`}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		for _, link := range tt.links {
+			header.Add("Link", link)
+		}
+		if got := nextLink(header); got != tt.expected {
+			t.Errorf("Expected %q for %v, got: %q", tt.expected, tt.links, got)
+		}
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	rule, err := newPaginationRule(&PaginationConfig{Strategy: "cursor", CursorParam: "after", NextCursor: "$.next"})
+	if err != nil {
+		t.Fatalf("Failed to create rule: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/items?after=5", nil)
+
+	tests := []struct {
+		doc      string
+		expected string
+	}{
+		{doc: `{"next": "abc"}`, expected: "abc"},
+		{doc: `{"next": 12345678}`, expected: "12345678"},
+		{doc: `{"next": 5}`, expected: ""},
+		{doc: `{"next": null}`, expected: ""},
+	}
+
+	for _, tt := range tests {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+			t.Fatalf("Failed to parse %s: %v", tt.doc, err)
+		}
+		if got := rule.next(req, &http.Response{Header: http.Header{}}, doc, 1, 1); got != tt.expected {
+			t.Errorf("Expected cursor %q for %s, got: %q", tt.expected, tt.doc, got)
+		}
+	}
+}
+
+// paginatedUpstream serves seven items in pages of three by default,
+// supporting every pagination strategy. Requests for failPage fail.
+func paginatedUpstream(failPage int) *httptest.Server {
+	const total = 7
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		size := 3
+		if n, err := strconv.Atoi(q.Get("limit") + q.Get("pageSize")); err == nil {
+			size = n
+		}
+
+		start := 0
+		switch r.URL.Path {
+		case "/link", "/page":
+			page, _ := strconv.Atoi(q.Get("page"))
+			start = max(page-1, 0) * size
+		case "/cursor":
+			start, _ = strconv.Atoi(strings.TrimPrefix(q.Get("cursor"), "c"))
+		case "/offset":
+			start, _ = strconv.Atoi(q.Get("offset"))
+		}
+		if failPage > 0 && start/size+1 == failPage {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		items := []int{}
+		for i := start; i < min(start+size, total); i++ {
+			items = append(items, i+1)
+		}
+		body := map[string]any{"data": items}
+		if start+size < total {
+			switch r.URL.Path {
+			case "/link":
+				w.Header().Set("Link", fmt.Sprintf(`</link?page=%d>; rel="next"`, start/size+2))
+			case "/cursor":
+				body["meta"] = map[string]any{"next": fmt.Sprintf("c%d", start+size)}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
+	}))
+}
+
+func paginatedSpec(serverURL string) *parser.OpenAPISpec {
+	query := func(names ...string) []parser.Parameter {
+		var params []parser.Parameter
+		for _, name := range names {
+			params = append(params, parser.Parameter{Name: name, In: "query", Schema: &parser.Schema{Type: "string"}})
+		}
+		return params
+	}
+	return &parser.OpenAPISpec{
+		Servers: []parser.Server{{URL: serverURL}},
+		Paths: map[string]parser.PathItem{
+			"/link":   {Get: &parser.Operation{OperationID: "listLink", Parameters: query("page")}},
+			"/cursor": {Get: &parser.Operation{OperationID: "listCursor", Parameters: query("cursor")}},
+			"/offset": {Get: &parser.Operation{OperationID: "listOffset", Parameters: query("offset", "limit")}},
+			"/page":   {Get: &parser.Operation{OperationID: "listPage", Parameters: query("page", "pageSize")}},
+		},
+	}
+}
+
+type collectedPages struct {
+	Items             []int  `json:"items"`
+	ContinuationToken string `json:"continuationToken"`
+	Error             string `json:"error"`
+}
+
+func callPaginated(t *testing.T, gen *MCPGenerator, name, args string) (*CallToolResult, collectedPages) {
+	t.Helper()
+
+	result, err := gen.ExecuteTool(context.Background(), name, json.RawMessage(args))
+	if err != nil {
+		t.Fatalf("Failed to execute tool: %v", err)
+	}
+	var collected collectedPages
+	if !result.IsError {
+		if err := json.Unmarshal([]byte(result.Content[0].Text), &collected); err != nil {
+			t.Fatalf("Failed to decode result: %v", err)
+		}
+	}
+	return result, collected
+}
+
+func TestExecuteToolPagination(t *testing.T) {
+	upstream := paginatedUpstream(0)
+	defer upstream.Close()
+
+	cfg := &Config{Operations: map[string]OperationConfig{
+		"listLink":   {Pagination: &PaginationConfig{Strategy: "link"}},
+		"listCursor": {Pagination: &PaginationConfig{Strategy: "cursor", CursorParam: "cursor", NextCursor: "$.meta.next", Items: "$.data"}},
+		"listOffset": {Pagination: &PaginationConfig{Strategy: "offset"}},
+		"listPage":   {Pagination: &PaginationConfig{Strategy: "page"}},
+	}}
+	gen := NewMCPGeneratorWithConfig(paginatedSpec(upstream.URL), cfg)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		tool  string
+		args  string
+		pages int
+	}{
+		{name: "Link header", tool: "listLink", args: `{}`, pages: 3},
+		{name: "Cursor", tool: "listCursor", args: `{}`, pages: 3},
+		{name: "Offset and limit", tool: "listOffset", args: `{"limit": "4"}`, pages: 2},
+		{name: "Page and page size", tool: "listPage", args: `{}`, pages: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, collected := callPaginated(t, gen, tt.tool, tt.args)
+			if result.IsError {
+				t.Fatalf("Expected success, got: %s", result.Content[0].Text)
+			}
+			if fmt.Sprint(collected.Items) != "[1 2 3 4 5 6 7]" {
+				t.Errorf("Expected every item, got: %v", collected.Items)
+			}
+			if collected.ContinuationToken != "" {
+				t.Errorf("Expected no continuation token, got: %s", collected.ContinuationToken)
+			}
+			pagination, _ := result.Meta["specmill/pagination"].(map[string]any)
+			if pagination["pages"] != tt.pages || pagination["items"] != 7 {
+				t.Errorf("Unexpected pagination metadata: %v", pagination)
+			}
+		})
+	}
+}
+
+func TestExecuteToolPaginationLimits(t *testing.T) {
+	upstream := paginatedUpstream(0)
+	defer upstream.Close()
+
+	tests := []struct {
+		tool   string
+		config PaginationConfig
+		first  string
+		rest   string
+	}{
+		{tool: "listLink", config: PaginationConfig{Strategy: "link", MaxPages: 2}, first: "[1 2 3 4 5 6]", rest: "[7]"},
+		{tool: "listOffset", config: PaginationConfig{Strategy: "offset", MaxItems: 5}, first: "[1 2 3 4 5]", rest: "[6 7]"},
+		{tool: "listPage", config: PaginationConfig{Strategy: "page", MaxPages: 2}, first: "[1 2 3 4 5 6]", rest: "[7]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.config.Strategy, func(t *testing.T) {
+			cfg := &Config{Operations: map[string]OperationConfig{tt.tool: {Pagination: &tt.config}}}
+			gen := NewMCPGeneratorWithConfig(paginatedSpec(upstream.URL), cfg)
+			if err := gen.GenerateTools(); err != nil {
+				t.Fatalf("Failed to generate tools: %v", err)
+			}
+
+			_, first := callPaginated(t, gen, tt.tool, `{}`)
+			if fmt.Sprint(first.Items) != tt.first || first.ContinuationToken == "" {
+				t.Fatalf("Expected %s and a continuation token, got: %+v", tt.first, first)
+			}
+
+			_, rest := callPaginated(t, gen, tt.tool, `{"continuationToken": "`+first.ContinuationToken+`"}`)
+			if fmt.Sprint(rest.Items) != tt.rest || rest.ContinuationToken != "" {
+				t.Errorf("Expected the remaining %s, got: %+v", tt.rest, rest)
+			}
+
+			result, _ := callPaginated(t, gen, tt.tool, `{"continuationToken": "bogus"}`)
+			if !result.IsError || !strings.Contains(result.Content[0].Text, "invalid continuationToken") {
+				t.Errorf("Expected invalid token error, got: %s", result.Content[0].Text)
+			}
+		})
+	}
+}
+
+func TestExecuteToolPaginationItemLimit(t *testing.T) {
+	upstream := paginatedUpstream(0)
+	defer upstream.Close()
+
+	strategies := map[string]PaginationConfig{
+		"listLink":   {Strategy: "link"},
+		"listCursor": {Strategy: "cursor", CursorParam: "cursor", NextCursor: "$.meta.next", Items: "$.data"},
+		"listOffset": {Strategy: "offset"},
+		"listPage":   {Strategy: "page"},
+	}
+
+	for tool, config := range strategies {
+		for _, maxItems := range []int{2, 4} {
+			t.Run(fmt.Sprintf("%s/%d", config.Strategy, maxItems), func(t *testing.T) {
+				config.MaxItems = maxItems
+				cfg := &Config{Operations: map[string]OperationConfig{tool: {Pagination: &config}}}
+				gen := NewMCPGeneratorWithConfig(paginatedSpec(upstream.URL), cfg)
+				if err := gen.GenerateTools(); err != nil {
+					t.Fatalf("Failed to generate tools: %v", err)
+				}
+
+				var all []int
+				args := `{}`
+				for calls := 0; calls < 7; calls++ {
+					_, collected := callPaginated(t, gen, tool, args)
+					if len(collected.Items) > maxItems {
+						t.Fatalf("Expected at most %d items, got: %v", maxItems, collected.Items)
+					}
+					all = append(all, collected.Items...)
+					if collected.ContinuationToken == "" {
+						break
+					}
+					args = `{"continuationToken": "` + collected.ContinuationToken + `"}`
+				}
+				if fmt.Sprint(all) != "[1 2 3 4 5 6 7]" {
+					t.Errorf("Expected every item exactly once across calls, got: %v", all)
+				}
+			})
+		}
+	}
+}
+
+func TestExecuteToolPaginationFailure(t *testing.T) {
+	upstream := paginatedUpstream(2)
+	defer upstream.Close()
+
+	cfg := &Config{Operations: map[string]OperationConfig{
+		"listOffset": {Pagination: &PaginationConfig{Strategy: "offset"}},
+	}}
+	gen := NewMCPGeneratorWithConfig(paginatedSpec(upstream.URL), cfg)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	_, collected := callPaginated(t, gen, "listOffset", `{}`)
+	if fmt.Sprint(collected.Items) != "[1 2 3]" {
+		t.Errorf("Expected the first page, got: %v", collected.Items)
+	}
+	if !strings.Contains(collected.Error, "HTTP 503") || collected.ContinuationToken == "" {
+		t.Errorf("Expected error and token to resume, got: %+v", collected)
+	}
+}
+
+func TestPaginationExtension(t *testing.T) {
+	spec, err := parser.ParseOpenAPISpecData([]byte(`
+openapi: 3.0.0
+info:
+  title: Items API
+  version: 1.0.0
+paths:
+  /items:
+    get:
+      operationId: listItems
+      x-specmill-pagination:
+        strategy: cursor
+        cursorParam: after
+        nextCursor: $.next
+        maxPages: 3
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  items:
+                    type: array
+  /broken:
+    get:
+      operationId: listBroken
+      responses:
+        "200":
+          description: OK
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	gen := NewMCPGenerator(spec)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	var tool *MCPTool
+	for i := range gen.GetTools() {
+		if gen.GetTools()[i].Name == "listItems" {
+			tool = &gen.GetTools()[i]
+		}
+	}
+	if tool == nil {
+		t.Fatal("Expected listItems tool")
+	}
+	if !strings.Contains(string(tool.InputSchema), `"continuationToken"`) {
+		t.Errorf("Expected continuationToken argument, got: %s", tool.InputSchema)
+	}
+	if tool.OutputSchema != nil {
+		t.Errorf("Expected no output schema for collected pages, got: %s", tool.OutputSchema)
+	}
+	if !strings.Contains(tool.Description, "up to 3 pages") {
+		t.Errorf("Expected pagination in description, got: %s", tool.Description)
+	}
+
+	cfg := &Config{Operations: map[string]OperationConfig{
+		"listBroken": {Pagination: &PaginationConfig{Strategy: "cursor"}},
+	}}
+	gen = NewMCPGeneratorWithConfig(spec, cfg)
+	if err := gen.GenerateTools(); err == nil || !strings.Contains(err.Error(), "listBroken") {
+		t.Errorf("Expected invalid pagination config error, got: %v", err)
+	}
+}
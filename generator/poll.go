@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+const defaultPollInterval = 30 * time.Second
+
+// ResourceState identifies a version of a resource's contents.
+type ResourceState struct {
+	ETag   string
+	Digest string
+}
+
+// PollInterval returns how often a subscribed resource should be polled. It
+// is zero for resources that are not backed by an upstream operation.
+func (g *MCPGenerator) PollInterval(uri string) time.Duration {
+	resource, _ := g.findAPIResource(uri)
+	if resource == nil {
+		return 0
+	}
+	if interval := g.config.Operations[resource.ref.operation.OperationID].PollInterval; interval > 0 {
+		return interval
+	}
+	if g.config.PollInterval > 0 {
+		return g.config.PollInterval
+	}
+	return defaultPollInterval
+}
+
+// PollResource fetches a resource and reports whether its contents differ
+// from prev. Upstream requests carry prev's ETag as If-None-Match. A zero
+// prev never counts as changed, so the first poll only records a baseline.
+func (g *MCPGenerator) PollResource(ctx context.Context, uri string, prev ResourceState) (ResourceState, bool, error) {
+	var state ResourceState
+
+	if resource, _ := g.findAPIResource(uri); resource == nil {
+		result, err := g.ReadResource(ctx, uri)
+		if err != nil {
+			return prev, false, err
+		}
+		hash := sha256.New()
+		for _, contents := range result.Contents {
+			hash.Write([]byte(contents.Text))
+		}
+		state.Digest = hex.EncodeToString(hash.Sum(nil))
+	} else {
+		resp, data, err := g.fetchAPIResource(ctx, uri, prev.ETag)
+		if err != nil {
+			return prev, false, err
+		}
+		if resp.StatusCode == http.StatusNotModified {
+			return prev, false, nil
+		}
+		sum := sha256.Sum256(data)
+		state = ResourceState{ETag: resp.Header.Get("ETag"), Digest: hex.EncodeToString(sum[:])}
+	}
+
+	changed := prev.Digest != "" && prev.Digest != state.Digest
+	return state, changed, nil
+}
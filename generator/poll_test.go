@@ -0,0 +1,96 @@
+package generator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPollResource(t *testing.T) {
+	var mu sync.Mutex
+	body, etag := `{"status":"pending"}`, `"v1"`
+	var ifNoneMatch string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		ifNoneMatch = r.Header.Get("If-None-Match")
+		if ifNoneMatch == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(body))
+	}))
+	defer upstream.Close()
+
+	cfg := &Config{Operations: map[string]OperationConfig{"getInventory": {Resource: true}}}
+	gen := NewMCPGeneratorWithConfig(apiResourceTestSpec(upstream.URL), cfg)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	const uri = "specmill://api/store/inventory"
+
+	state, changed, err := gen.PollResource(context.Background(), uri, ResourceState{})
+	if err != nil {
+		t.Fatalf("Failed to poll resource: %v", err)
+	}
+	if changed || state.ETag != `"v1"` {
+		t.Errorf("Expected baseline with ETag v1, got: %+v (changed: %v)", state, changed)
+	}
+
+	next, changed, err := gen.PollResource(context.Background(), uri, state)
+	if err != nil {
+		t.Fatalf("Failed to poll resource: %v", err)
+	}
+	if ifNoneMatch != `"v1"` {
+		t.Errorf("Expected If-None-Match v1, got: %s", ifNoneMatch)
+	}
+	if changed || next != state {
+		t.Errorf("Expected unchanged state on 304, got: %+v (changed: %v)", next, changed)
+	}
+
+	mu.Lock()
+	body, etag = `{"status":"done"}`, `"v2"`
+	mu.Unlock()
+
+	next, changed, err = gen.PollResource(context.Background(), uri, state)
+	if err != nil {
+		t.Fatalf("Failed to poll resource: %v", err)
+	}
+	if !changed || next.ETag != `"v2"` {
+		t.Errorf("Expected change to v2, got: %+v (changed: %v)", next, changed)
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	cfg := &Config{
+		PollInterval: time.Minute,
+		Operations: map[string]OperationConfig{
+			"getPet":       {Resource: true, PollInterval: 5 * time.Second},
+			"getInventory": {Resource: true},
+		},
+	}
+	gen := NewMCPGeneratorWithConfig(apiResourceTestSpec("https://api.example.com"), cfg)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	tests := []struct {
+		uri      string
+		expected time.Duration
+	}{
+		{uri: "specmill://api/pets/1", expected: 5 * time.Second},
+		{uri: "specmill://api/store/inventory", expected: time.Minute},
+		{uri: "specmill://spec", expected: 0},
+	}
+
+	for _, tt := range tests {
+		if interval := gen.PollInterval(tt.uri); interval != tt.expected {
+			t.Errorf("Expected interval %v for %s, got: %v", tt.expected, tt.uri, interval)
+		}
+	}
+}
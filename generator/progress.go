@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// progressInterval throttles byte progress so that large transfers do not
+// flood the client with notifications.
+const progressInterval = 200 * time.Millisecond
+
+// ProgressFunc receives progress updates for a tool call. progress increases
+// with every update; total is 0 when it is not known.
+type ProgressFunc func(progress, total float64, message string)
+
+type progressKey struct{}
+
+// WithProgress returns a context under which ExecuteTool reports the bytes
+// sent to and received from the upstream, and other steps of long calls, to
+// report.
+func WithProgress(ctx context.Context, report ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, &progressTracker{report: report})
+}
+
+// progressTracker accumulates the progress of one call. Bytes transferred
+// are reported against the expected byte total until the call takes its
+// first discrete step; from then on progress counts steps, continuing from
+// the last reported value, with an unknown total. Its methods are safe to
+// call on a nil tracker, which discards updates.
+type progressTracker struct {
+	mu       sync.Mutex
+	report   ProgressFunc
+	bytes    float64
+	total    float64
+	steps    float64
+	base     float64
+	reported float64
+	last     time.Time
+}
+
+func progressFromContext(ctx context.Context) *progressTracker {
+	tracker, _ := ctx.Value(progressKey{}).(*progressTracker)
+	return tracker
+}
+
+// expect adds n bytes to the expected total.
+func (p *progressTracker) expect(n float64) {
+	if p == nil || n <= 0 {
+		return
+	}
+	p.mu.Lock()
+	p.total += n
+	p.mu.Unlock()
+}
+
+// advance records delta bytes transferred.
+func (p *progressTracker) advance(delta float64, message string, force bool) {
+	p.update(func() { p.bytes += delta }, message, force)
+}
+
+// advanceSteps records delta discrete steps, such as events received.
+func (p *progressTracker) advanceSteps(delta float64, message string, force bool) {
+	p.update(func() {
+		if p.steps == 0 {
+			p.base = p.reported
+		}
+		p.steps += delta
+	}, message, force)
+}
+
+// step reports a discrete step of a call, such as a page fetched or a
+// status poll.
+func (p *progressTracker) step(message string) {
+	p.advanceSteps(1, message, true)
+}
+
+// update applies record and reports the resulting progress. Updates within
+// progressInterval of the previous one are dropped unless force is set, and
+// updates that would not increase the reported progress are never sent.
+func (p *progressTracker) update(record func(), message string, force bool) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	record()
+	progress, total := p.bytes, p.total
+	if p.steps > 0 {
+		progress, total = p.base+p.steps, 0
+	} else if total < progress {
+		total = 0
+	}
+	now := time.Now()
+	if progress <= p.reported || (!force && now.Sub(p.last) < progressInterval) {
+		p.mu.Unlock()
+		return
+	}
+	p.last = now
+	p.reported = progress
+	p.mu.Unlock()
+
+	p.report(progress, total, message)
+}
+
+// progressReader reports the bytes read from r as they are transferred.
+type progressReader struct {
+	r       io.ReadCloser
+	tracker *progressTracker
+	verb    string
+	n       int64
+	size    int64
+	done    bool
+}
+
+func newProgressReader(r io.ReadCloser, tracker *progressTracker, verb string, size int64) *progressReader {
+	if size > 0 {
+		tracker.expect(float64(size))
+	}
+	return &progressReader{r: r, tracker: tracker, verb: verb, size: size}
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.n += int64(n)
+
+	eof := err == io.EOF && !r.done
+	if n > 0 || eof {
+		r.done = r.done || eof
+		r.tracker.advance(float64(n), r.message(), eof)
+	}
+	return n, err
+}
+
+func (r *progressReader) Close() error {
+	return r.r.Close()
+}
+
+func (r *progressReader) message() string {
+	if r.size > 0 {
+		return fmt.Sprintf("%s %s of %s", r.verb, formatBytes(r.n), formatBytes(r.size))
+	}
+	return fmt.Sprintf("%s %s", r.verb, formatBytes(r.n))
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"specmill/parser"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n        int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5.0 MiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.expected {
+			t.Errorf("Expected %s for %d, got: %s", tt.expected, tt.n, got)
+		}
+	}
+}
+
+type progressUpdate struct {
+	progress, total float64
+	message         string
+}
+
+func TestProgressTrackerSteps(t *testing.T) {
+	var updates []progressUpdate
+	tracker := &progressTracker{report: func(progress, total float64, message string) {
+		updates = append(updates, progressUpdate{progress, total, message})
+	}}
+
+	tracker.expect(100)
+	tracker.advance(60, "Downloaded 60 B of 100 B", true)
+	tracker.advance(40, "Downloaded 100 B of 100 B", true)
+	tracker.step("Polled operation status (attempt 1): running")
+	tracker.expect(500)
+	tracker.advance(500, "Downloaded 500 B of 500 B", true)
+	tracker.step("Polled operation status (attempt 2): succeeded")
+
+	expected := []progressUpdate{
+		{60, 100, "Downloaded 60 B of 100 B"},
+		{100, 100, "Downloaded 100 B of 100 B"},
+		{101, 0, "Polled operation status (attempt 1): running"},
+		{102, 0, "Polled operation status (attempt 2): succeeded"},
+	}
+	if len(updates) != len(expected) {
+		t.Fatalf("Expected %d updates, got: %+v", len(expected), updates)
+	}
+	for i := range expected {
+		if updates[i] != expected[i] {
+			t.Errorf("Expected update %+v, got: %+v", expected[i], updates[i])
+		}
+	}
+}
+
+func TestExecuteToolProgress(t *testing.T) {
+	payload := strings.Repeat("x", 4096)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		body := `{"data":"` + payload + `"}`
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Write([]byte(body))
+	}))
+	defer upstream.Close()
+
+	spec := &parser.OpenAPISpec{
+		Servers: []parser.Server{{URL: upstream.URL}},
+		Paths: map[string]parser.PathItem{
+			"/uploads": {
+				Post: &parser.Operation{
+					OperationID: "upload",
+					RequestBody: &parser.RequestBody{
+						Content: map[string]parser.MediaType{
+							"application/json": {Schema: &parser.Schema{Type: "object"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	gen := NewMCPGenerator(spec)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	var updates []progressUpdate
+	ctx := WithProgress(context.Background(), func(progress, total float64, message string) {
+		updates = append(updates, progressUpdate{progress, total, message})
+	})
+
+	args, _ := json.Marshal(map[string]any{"body": map[string]string{"data": payload}})
+	result, err := gen.ExecuteTool(ctx, "upload", args)
+	if err != nil {
+		t.Fatalf("Failed to execute tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected success, got: %s", result.Content[0].Text)
+	}
+
+	if len(updates) < 2 {
+		t.Fatalf("Expected upload and download progress, got: %+v", updates)
+	}
+	for i := 1; i < len(updates); i++ {
+		if updates[i].progress <= updates[i-1].progress {
+			t.Errorf("Expected increasing progress, got: %+v", updates)
+		}
+	}
+	if !strings.HasPrefix(updates[0].message, "Uploaded ") {
+		t.Errorf("Expected upload progress first, got: %s", updates[0].message)
+	}
+	last := updates[len(updates)-1]
+	if !strings.HasPrefix(last.message, "Downloaded ") {
+		t.Errorf("Expected download progress last, got: %s", last.message)
+	}
+	if last.total == 0 || last.progress != last.total {
+		t.Errorf("Expected completed progress, got: %+v", last)
+	}
+}
+
+func TestExecuteToolWithoutProgress(t *testing.T) {
+	tracker := progressFromContext(context.Background())
+	if tracker != nil {
+		t.Fatal("Expected no tracker without WithProgress")
+	}
+	// Updates on a nil tracker are discarded.
+	tracker.expect(10)
+	tracker.step("ignored")
+}
@@ -0,0 +1,339 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"specmill/parser"
+)
+
+var (
+	ErrPromptNotFound         = errors.New("prompt not found")
+	ErrInvalidPromptArguments = errors.New("invalid prompt arguments")
+)
+
+type prompt struct {
+	MCPPrompt
+	render func(args map[string]string) string
+	// schemas holds the input schema of arguments that map to tool
+	// arguments, used for completion.
+	schemas map[string]interface{}
+}
+
+func (g *MCPGenerator) GetPrompts() []MCPPrompt {
+	prompts := make([]MCPPrompt, 0, len(g.prompts))
+	for _, p := range g.prompts {
+		prompts = append(prompts, p.MCPPrompt)
+	}
+	return prompts
+}
+
+// GetPrompt renders a prompt with the given arguments. Missing required
+// arguments produce an error wrapping ErrInvalidPromptArguments.
+func (g *MCPGenerator) GetPrompt(name string, args map[string]string) (*GetPromptResult, error) {
+	found := g.findPrompt(name)
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", ErrPromptNotFound, name)
+	}
+
+	for _, arg := range found.Arguments {
+		if arg.Required && args[arg.Name] == "" {
+			return nil, fmt.Errorf("%w: missing required argument %s", ErrInvalidPromptArguments, arg.Name)
+		}
+	}
+
+	return &GetPromptResult{
+		Description: found.Description,
+		Messages: []PromptMessage{
+			{
+				Role:    "user",
+				Content: ToolContent{Type: "text", Text: found.render(args)},
+			},
+		},
+	}, nil
+}
+
+func (g *MCPGenerator) findPrompt(name string) *prompt {
+	for _, p := range g.prompts {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// generatePrompts builds the configured prompt templates followed by one
+// prompt per tag and one per operation. Generated prompts never replace a
+// configured prompt of the same name.
+func (g *MCPGenerator) generatePrompts() error {
+	g.prompts = nil
+	seen := make(map[string]bool)
+
+	for _, cfg := range g.config.Prompts {
+		if cfg.Name == "" {
+			return fmt.Errorf("prompt without a name")
+		}
+		if seen[cfg.Name] {
+			return fmt.Errorf("duplicate prompt %s", cfg.Name)
+		}
+		for _, name := range cfg.Tools {
+			if _, ok := g.operations[name]; !ok {
+				return fmt.Errorf("prompt %s references unknown tool %s", cfg.Name, name)
+			}
+		}
+		seen[cfg.Name] = true
+		g.prompts = append(g.prompts, g.configuredPrompt(cfg))
+	}
+
+	for _, tag := range g.tags() {
+		p := g.tagPrompt(tag)
+		if !seen[p.Name] {
+			seen[p.Name] = true
+			g.prompts = append(g.prompts, p)
+		}
+	}
+
+	for _, tool := range g.tools {
+		p := g.operationPrompt(g.operations[tool.Name])
+		if !seen[p.Name] {
+			seen[p.Name] = true
+			g.prompts = append(g.prompts, p)
+		}
+	}
+
+	return nil
+}
+
+func (g *MCPGenerator) configuredPrompt(cfg PromptConfig) *prompt {
+	return &prompt{
+		MCPPrompt: MCPPrompt{
+			Name:        cfg.Name,
+			Description: cfg.Description,
+			Arguments:   cfg.Arguments,
+		},
+		render: func(args map[string]string) string {
+			text := cfg.Template
+			for _, arg := range cfg.Arguments {
+				text = strings.ReplaceAll(text, "{{"+arg.Name+"}}", args[arg.Name])
+			}
+			if len(cfg.Tools) > 0 {
+				text += "\n\nTools:\n"
+				for _, name := range cfg.Tools {
+					text += toolLine(g.operations[name])
+				}
+			}
+			return text
+		},
+	}
+}
+
+// tags returns the tags of the generated tools, in the order the spec
+// declares them followed by undeclared tags in tool order.
+func (g *MCPGenerator) tags() []string {
+	used := make(map[string]bool)
+	var undeclared []string
+	for _, tool := range g.tools {
+		for _, tag := range g.operations[tool.Name].operation.Tags {
+			if !used[tag] {
+				used[tag] = true
+				undeclared = append(undeclared, tag)
+			}
+		}
+	}
+
+	var tags []string
+	declared := make(map[string]bool)
+	for _, tag := range g.spec.Tags {
+		if used[tag.Name] && !declared[tag.Name] {
+			declared[tag.Name] = true
+			tags = append(tags, tag.Name)
+		}
+	}
+	for _, tag := range undeclared {
+		if !declared[tag] {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (g *MCPGenerator) tagPrompt(tag string) *prompt {
+	description := fmt.Sprintf("Work with the %s API", tag)
+	for _, t := range g.spec.Tags {
+		if t.Name == tag && t.Description != "" {
+			description = fmt.Sprintf("%s: %s", description, t.Description)
+		}
+	}
+
+	var refs []*operationRef
+	for _, tool := range g.tools {
+		ref := g.operations[tool.Name]
+		for _, t := range ref.operation.Tags {
+			if t == tag {
+				refs = append(refs, ref)
+				break
+			}
+		}
+	}
+
+	return &prompt{
+		MCPPrompt: MCPPrompt{
+			Name:        "explore_" + tag,
+			Description: description,
+			Arguments: []PromptArgument{
+				{Name: "task", Description: "What you want to accomplish"},
+			},
+		},
+		render: func(args map[string]string) string {
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s.\n\nOperations:\n", description)
+			for _, ref := range refs {
+				b.WriteString(toolLine(ref))
+			}
+			if relationships := schemaRelationships(refs); relationships != "" {
+				fmt.Fprintf(&b, "\nRelationships:\n%s", relationships)
+			}
+			if task := args["task"]; task != "" {
+				fmt.Fprintf(&b, "\nTask: %s\n", task)
+			}
+			return b.String()
+		},
+	}
+}
+
+func (g *MCPGenerator) operationPrompt(ref *operationRef) *prompt {
+	op := ref.operation
+
+	var arguments []PromptArgument
+	for _, param := range op.Parameters {
+		name := param.Name
+		if param.In == "header" {
+			name = "header_" + name
+		} else if param.In != "path" && param.In != "query" {
+			continue
+		}
+		if _, ok := ref.pinned[name]; ok {
+			continue
+		}
+		arguments = append(arguments, PromptArgument{
+			Name:        name,
+			Description: param.Description,
+			Required:    param.Required,
+		})
+	}
+
+	description := op.Summary
+	if description == "" {
+		description = fmt.Sprintf("%s %s", strings.ToUpper(ref.method), ref.path)
+	}
+
+	properties, _ := ref.inputSchema["properties"].(map[string]interface{})
+
+	return &prompt{
+		MCPPrompt: MCPPrompt{
+			Name:        op.OperationID,
+			Description: description,
+			Arguments:   arguments,
+		},
+		schemas: properties,
+		render: func(args map[string]string) string {
+			var b strings.Builder
+			fmt.Fprintf(&b, "Call the `%s` tool (`%s %s`).\n", op.OperationID, strings.ToUpper(ref.method), ref.path)
+			if op.Summary != "" {
+				fmt.Fprintf(&b, "\n%s\n", op.Summary)
+			}
+			if op.Description != "" {
+				fmt.Fprintf(&b, "\n%s\n", op.Description)
+			}
+
+			var provided []string
+			for _, arg := range arguments {
+				if value := args[arg.Name]; value != "" {
+					provided = append(provided, fmt.Sprintf("- %s: %s\n", arg.Name, value))
+				}
+			}
+			if len(provided) > 0 {
+				b.WriteString("\nArguments:\n" + strings.Join(provided, ""))
+			}
+
+			if _, mediaType, ok := jsonRequestBody(op); ok {
+				if name := schemaRefName(mediaType.Schema); name != "" {
+					fmt.Fprintf(&b, "\nThe request body is a %s (%s%s).\n", name, schemaResourcePrefix, name)
+				} else {
+					b.WriteString("\nThe request body is described by the tool's input schema.\n")
+				}
+			}
+			return b.String()
+		},
+	}
+}
+
+func toolLine(ref *operationRef) string {
+	line := fmt.Sprintf("- `%s` (%s %s)", ref.operation.OperationID, strings.ToUpper(ref.method), ref.path)
+	if ref.operation.Summary != "" {
+		line += ": " + ref.operation.Summary
+	}
+	return line + "\n"
+}
+
+// schemaRelationships describes which operations return and which accept
+// each component schema, linking operations that work on the same entity.
+func schemaRelationships(refs []*operationRef) string {
+	returnedBy := make(map[string][]string)
+	acceptedBy := make(map[string][]string)
+
+	for _, ref := range refs {
+		op := ref.operation
+		if _, mediaType, ok := successJSONResponse(op.Responses); ok {
+			if name := schemaRefName(mediaType.Schema); name != "" {
+				returnedBy[name] = append(returnedBy[name], op.OperationID)
+			}
+		}
+		if _, mediaType, ok := jsonRequestBody(op); ok {
+			if name := schemaRefName(mediaType.Schema); name != "" {
+				acceptedBy[name] = append(acceptedBy[name], op.OperationID)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(returnedBy)+len(acceptedBy))
+	for name := range returnedBy {
+		names = append(names, name)
+	}
+	for name := range acceptedBy {
+		if _, ok := returnedBy[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		var parts []string
+		if ops := returnedBy[name]; len(ops) > 0 {
+			parts = append(parts, "returned by "+strings.Join(ops, ", "))
+		}
+		if ops := acceptedBy[name]; len(ops) > 0 {
+			parts = append(parts, "accepted by "+strings.Join(ops, ", "))
+		}
+		fmt.Fprintf(&b, "- %s: %s\n", name, strings.Join(parts, "; "))
+	}
+	return b.String()
+}
+
+// schemaRefName returns the component schema a schema refers to, directly
+// or as the items of an array.
+func schemaRefName(schema *parser.Schema) string {
+	if schema == nil {
+		return ""
+	}
+	if schema.Ref == "" && schema.Items != nil {
+		schema = schema.Items
+	}
+	if !strings.HasPrefix(schema.Ref, "#/components/schemas/") {
+		return ""
+	}
+	return strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+}
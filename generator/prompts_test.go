@@ -0,0 +1,161 @@
+package generator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"specmill/parser"
+)
+
+func promptTestSpec() *parser.OpenAPISpec {
+	petRef := &parser.Schema{Ref: "#/components/schemas/Pet"}
+
+	return &parser.OpenAPISpec{
+		Tags: []parser.Tag{{Name: "pet", Description: "Everything about your Pets"}},
+		Paths: map[string]parser.PathItem{
+			"/pets": {
+				Post: &parser.Operation{
+					OperationID: "addPet",
+					Summary:     "Add a new pet",
+					Tags:        []string{"pet"},
+					RequestBody: &parser.RequestBody{
+						Content: map[string]parser.MediaType{"application/json": {Schema: petRef}},
+					},
+				},
+			},
+			"/pets/{petId}": {
+				Get: &parser.Operation{
+					OperationID: "getPetById",
+					Summary:     "Find pet by ID",
+					Tags:        []string{"pet"},
+					Parameters: []parser.Parameter{
+						{Name: "petId", In: "path", Required: true, Description: "ID of pet", Schema: &parser.Schema{Type: "integer"}},
+					},
+					Responses: map[string]parser.Response{
+						"200": {Content: map[string]parser.MediaType{"application/json": {Schema: petRef}}},
+					},
+				},
+			},
+			"/store/inventory": {
+				Get: &parser.Operation{
+					OperationID: "getInventory",
+					Tags:        []string{"store"},
+				},
+			},
+		},
+		Components: &parser.Components{
+			Schemas: map[string]*parser.Schema{"Pet": {Type: "object"}},
+		},
+	}
+}
+
+func TestGetPrompts(t *testing.T) {
+	cfg := &Config{Prompts: []PromptConfig{
+		{
+			Name:      "adopt",
+			Arguments: []PromptArgument{{Name: "name", Required: true}},
+			Tools:     []string{"addPet"},
+			Template:  "Adopt a pet called {{name}}.",
+		},
+	}}
+	gen := NewMCPGeneratorWithConfig(promptTestSpec(), cfg)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	expected := []string{"adopt", "explore_pet", "explore_store", "addPet", "getPetById", "getInventory"}
+	prompts := gen.GetPrompts()
+	if len(prompts) != len(expected) {
+		t.Fatalf("Expected %d prompts, got: %+v", len(expected), prompts)
+	}
+	for i, name := range expected {
+		if prompts[i].Name != name {
+			t.Errorf("Expected prompt %d to be %s, got: %s", i, name, prompts[i].Name)
+		}
+	}
+
+	if prompts[1].Description != "Work with the pet API: Everything about your Pets" {
+		t.Errorf("Expected tag description, got: %s", prompts[1].Description)
+	}
+	if len(prompts[4].Arguments) != 1 || prompts[4].Arguments[0].Name != "petId" || !prompts[4].Arguments[0].Required {
+		t.Errorf("Expected required petId argument, got: %+v", prompts[4].Arguments)
+	}
+}
+
+func TestGetPrompt(t *testing.T) {
+	cfg := &Config{Prompts: []PromptConfig{
+		{
+			Name:      "adopt",
+			Arguments: []PromptArgument{{Name: "name", Required: true}},
+			Tools:     []string{"addPet"},
+			Template:  "Adopt a pet called {{name}}.",
+		},
+	}}
+	gen := NewMCPGeneratorWithConfig(promptTestSpec(), cfg)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     map[string]string
+		contains []string
+	}{
+		{
+			name:     "adopt",
+			args:     map[string]string{"name": "Rex"},
+			contains: []string{"Adopt a pet called Rex.", "- `addPet` (POST /pets): Add a new pet"},
+		},
+		{
+			name: "explore_pet",
+			args: map[string]string{"task": "rename a pet"},
+			contains: []string{
+				"- `getPetById` (GET /pets/{petId}): Find pet by ID",
+				"- Pet: returned by getPetById; accepted by addPet",
+				"Task: rename a pet",
+			},
+		},
+		{
+			name:     "getPetById",
+			args:     map[string]string{"petId": "7"},
+			contains: []string{"Call the `getPetById` tool (`GET /pets/{petId}`).", "- petId: 7"},
+		},
+		{
+			name:     "addPet",
+			contains: []string{"The request body is a Pet (specmill://schemas/Pet)."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := gen.GetPrompt(tt.name, tt.args)
+			if err != nil {
+				t.Fatalf("Failed to get prompt: %v", err)
+			}
+			if len(result.Messages) != 1 || result.Messages[0].Role != "user" {
+				t.Fatalf("Expected a single user message, got: %+v", result.Messages)
+			}
+			for _, text := range tt.contains {
+				if !strings.Contains(result.Messages[0].Content.Text, text) {
+					t.Errorf("Expected prompt to contain %q, got: %s", text, result.Messages[0].Content.Text)
+				}
+			}
+		})
+	}
+
+	if _, err := gen.GetPrompt("adopt", nil); !errors.Is(err, ErrInvalidPromptArguments) {
+		t.Errorf("Expected ErrInvalidPromptArguments, got: %v", err)
+	}
+	if _, err := gen.GetPrompt("missing", nil); !errors.Is(err, ErrPromptNotFound) {
+		t.Errorf("Expected ErrPromptNotFound, got: %v", err)
+	}
+}
+
+func TestConfiguredPromptUnknownTool(t *testing.T) {
+	cfg := &Config{Prompts: []PromptConfig{{Name: "broken", Tools: []string{"missing"}}}}
+	gen := NewMCPGeneratorWithConfig(promptTestSpec(), cfg)
+	if err := gen.GenerateTools(); err == nil {
+		t.Error("Expected error for prompt referencing an unknown tool")
+	}
+}
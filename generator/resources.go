@@ -0,0 +1,277 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"specmill/parser"
+)
+
+const (
+	specResourceURI         = "specmill://spec"
+	schemaResourcePrefix    = "specmill://schemas/"
+	operationResourcePrefix = "specmill://operations/"
+)
+
+var ErrResourceNotFound = errors.New("resource not found")
+
+// SetRawSpec sets the original spec document served as the spec resource.
+// Without it the parsed spec is re-encoded as YAML.
+func (g *MCPGenerator) SetRawSpec(data []byte) {
+	g.rawSpec = data
+}
+
+// GetResources lists the spec document, each component schema and the
+// documentation of each generated tool's operation.
+func (g *MCPGenerator) GetResources() []MCPResource {
+	resources := []MCPResource{
+		{
+			URI:         specResourceURI,
+			Name:        "spec",
+			Description: fmt.Sprintf("OpenAPI document for %s", g.spec.Info.Title),
+			MimeType:    g.specMimeType(),
+		},
+	}
+
+	for _, name := range g.schemaNames() {
+		resources = append(resources, MCPResource{
+			URI:         schemaResourcePrefix + name,
+			Name:        name,
+			Description: fmt.Sprintf("Schema %s", name),
+			MimeType:    "application/json",
+		})
+	}
+
+	for _, r := range g.apiResources {
+		if uri := r.concreteURI(); uri != "" {
+			template := r.template()
+			resources = append(resources, MCPResource{
+				URI:         uri,
+				Name:        template.Name,
+				Description: template.Description,
+				MimeType:    template.MimeType,
+			})
+		}
+	}
+
+	for _, tool := range g.tools {
+		ref := g.operations[tool.Name]
+		resources = append(resources, MCPResource{
+			URI:         operationResourcePrefix + tool.Name,
+			Name:        tool.Name,
+			Description: fmt.Sprintf("Documentation for %s %s", strings.ToUpper(ref.method), ref.path),
+			MimeType:    "text/markdown",
+		})
+	}
+
+	return resources
+}
+
+// GetResourceTemplates returns the schema and operation documentation
+// templates followed by one template per GET operation exposed as a
+// resource.
+func (g *MCPGenerator) GetResourceTemplates() []MCPResourceTemplate {
+	templates := []MCPResourceTemplate{
+		{
+			URITemplate: schemaResourcePrefix + "{name}",
+			Name:        "schema",
+			Description: "A component schema of the API",
+			MimeType:    "application/json",
+		},
+		{
+			URITemplate: operationResourcePrefix + "{operationId}",
+			Name:        "operation",
+			Description: "Documentation for an API operation",
+			MimeType:    "text/markdown",
+		},
+	}
+
+	for _, r := range g.apiResources {
+		templates = append(templates, r.template())
+	}
+	return templates
+}
+
+// ReadResource returns the contents of a resource, or an error wrapping
+// ErrResourceNotFound when the URI does not name one.
+func (g *MCPGenerator) ReadResource(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	var text, mimeType string
+
+	switch {
+	case uri == specResourceURI:
+		data, err := g.specDocument()
+		if err != nil {
+			return nil, err
+		}
+		text, mimeType = string(data), g.specMimeType()
+	case strings.HasPrefix(uri, schemaResourcePrefix):
+		name := strings.TrimPrefix(uri, schemaResourcePrefix)
+		schema, ok := g.componentSchema(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+		}
+		data, err := schemaJSON(schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode schema %s: %w", name, err)
+		}
+		text, mimeType = string(data), "application/json"
+	case strings.HasPrefix(uri, apiResourcePrefix):
+		return g.readAPIResource(ctx, uri)
+	case strings.HasPrefix(uri, operationResourcePrefix):
+		ref, ok := g.operations[strings.TrimPrefix(uri, operationResourcePrefix)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+		}
+		text, mimeType = operationDocumentation(ref), "text/markdown"
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+
+	return &ReadResourceResult{
+		Contents: []ResourceContents{{URI: uri, MimeType: mimeType, Text: text}},
+	}, nil
+}
+
+func (g *MCPGenerator) specDocument() ([]byte, error) {
+	if g.rawSpec != nil {
+		return g.rawSpec, nil
+	}
+	data, err := yaml.Marshal(g.spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode spec: %w", err)
+	}
+	return data, nil
+}
+
+func (g *MCPGenerator) specMimeType() string {
+	if bytes.HasPrefix(bytes.TrimSpace(g.rawSpec), []byte("{")) {
+		return "application/json"
+	}
+	return "application/yaml"
+}
+
+func (g *MCPGenerator) schemaNames() []string {
+	if g.spec.Components == nil {
+		return nil
+	}
+	names := make([]string, 0, len(g.spec.Components.Schemas))
+	for name := range g.spec.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (g *MCPGenerator) componentSchema(name string) (*parser.Schema, bool) {
+	if g.spec.Components == nil {
+		return nil, false
+	}
+	schema, ok := g.spec.Components.Schemas[name]
+	return schema, ok && schema != nil
+}
+
+// schemaJSON encodes a schema as it appears in the spec, keeping $ref
+// pointers rather than resolving them.
+func schemaJSON(schema *parser.Schema) ([]byte, error) {
+	data, err := yaml.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(value, "", "  ")
+}
+
+// operationDocumentation renders an operation as Markdown.
+func operationDocumentation(ref *operationRef) string {
+	op := ref.operation
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n`%s %s`\n", op.OperationID, strings.ToUpper(ref.method), ref.path)
+	if op.Summary != "" {
+		fmt.Fprintf(&b, "\n%s\n", op.Summary)
+	}
+	if op.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", op.Description)
+	}
+
+	if len(op.Parameters) > 0 {
+		b.WriteString("\n## Parameters\n\n")
+		for _, param := range op.Parameters {
+			details := param.In
+			if param.Schema != nil && param.Schema.Type != "" {
+				details += ", " + param.Schema.Type
+			}
+			if param.Required {
+				details += ", required"
+			}
+			fmt.Fprintf(&b, "- `%s` (%s)", param.Name, details)
+			if param.Description != "" {
+				fmt.Fprintf(&b, ": %s", param.Description)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if op.RequestBody != nil {
+		b.WriteString("\n## Request Body\n")
+		if op.RequestBody.Description != "" {
+			fmt.Fprintf(&b, "\n%s\n", op.RequestBody.Description)
+		}
+		writeContentSchemas(&b, op.RequestBody.Content)
+	}
+
+	if len(op.Responses) > 0 {
+		b.WriteString("\n## Responses\n")
+		statuses := make([]string, 0, len(op.Responses))
+		for status := range op.Responses {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			response := op.Responses[status]
+			fmt.Fprintf(&b, "\n### %s\n", status)
+			if response.Description != "" {
+				fmt.Fprintf(&b, "\n%s\n", response.Description)
+			}
+			writeContentSchemas(&b, response.Content)
+		}
+	}
+
+	return b.String()
+}
+
+func writeContentSchemas(b *strings.Builder, content map[string]parser.MediaType) {
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+
+	for _, mediaType := range mediaTypes {
+		schema := content[mediaType].Schema
+		if schema == nil {
+			fmt.Fprintf(b, "\n`%s`\n", mediaType)
+			continue
+		}
+		if schema.Ref != "" {
+			name := schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+			fmt.Fprintf(b, "\n`%s`: [%s](%s%s)\n", mediaType, name, schemaResourcePrefix, name)
+			continue
+		}
+		data, err := schemaJSON(schema)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(b, "\n`%s`:\n\n```json\n%s\n```\n", mediaType, data)
+	}
+}
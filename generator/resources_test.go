@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"specmill/parser"
+)
+
+func resourceTestSpec() *parser.OpenAPISpec {
+	return &parser.OpenAPISpec{
+		Info: parser.Info{Title: "Pet API"},
+		Paths: map[string]parser.PathItem{
+			"/pets/{petId}": {
+				Get: &parser.Operation{
+					OperationID: "getPet",
+					Summary:     "Get a pet",
+					Parameters: []parser.Parameter{
+						{Name: "petId", In: "path", Required: true, Schema: &parser.Schema{Type: "integer"}},
+					},
+					Responses: map[string]parser.Response{
+						"200": {
+							Description: "A pet",
+							Content: map[string]parser.MediaType{
+								"application/json": {Schema: &parser.Schema{Ref: "#/components/schemas/Pet"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		Components: &parser.Components{
+			Schemas: map[string]*parser.Schema{
+				"Pet": {
+					Type:     "object",
+					Required: []string{"name"},
+					Properties: map[string]*parser.Schema{
+						"name":  {Type: "string"},
+						"owner": {Ref: "#/components/schemas/Owner"},
+					},
+				},
+				"Owner": {Type: "object"},
+			},
+		},
+	}
+}
+
+func TestGetResources(t *testing.T) {
+	gen := NewMCPGenerator(resourceTestSpec())
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	expected := []string{
+		"specmill://spec",
+		"specmill://schemas/Owner",
+		"specmill://schemas/Pet",
+		"specmill://operations/getPet",
+	}
+
+	resources := gen.GetResources()
+	if len(resources) != len(expected) {
+		t.Fatalf("Expected %d resources, got: %+v", len(expected), resources)
+	}
+	for i, uri := range expected {
+		if resources[i].URI != uri {
+			t.Errorf("Expected resource %d to be %s, got: %s", i, uri, resources[i].URI)
+		}
+	}
+}
+
+func TestReadResource(t *testing.T) {
+	gen := NewMCPGenerator(resourceTestSpec())
+	gen.SetRawSpec([]byte(`{"openapi": "3.0.0"}`))
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	tests := []struct {
+		uri      string
+		mimeType string
+		contains []string
+	}{
+		{
+			uri:      "specmill://spec",
+			mimeType: "application/json",
+			contains: []string{`"openapi": "3.0.0"`},
+		},
+		{
+			uri:      "specmill://schemas/Pet",
+			mimeType: "application/json",
+			contains: []string{`"$ref": "#/components/schemas/Owner"`, `"required": [`},
+		},
+		{
+			uri:      "specmill://operations/getPet",
+			mimeType: "text/markdown",
+			contains: []string{"`GET /pets/{petId}`", "- `petId` (path, integer, required)", "[Pet](specmill://schemas/Pet)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			result, err := gen.ReadResource(context.Background(), tt.uri)
+			if err != nil {
+				t.Fatalf("Failed to read resource: %v", err)
+			}
+			contents := result.Contents[0]
+			if contents.URI != tt.uri || contents.MimeType != tt.mimeType {
+				t.Errorf("Expected %s as %s, got: %s as %s", tt.uri, tt.mimeType, contents.URI, contents.MimeType)
+			}
+			for _, text := range tt.contains {
+				if !strings.Contains(contents.Text, text) {
+					t.Errorf("Expected contents to contain %q, got: %s", text, contents.Text)
+				}
+			}
+		})
+	}
+
+	for _, uri := range []string{"specmill://schemas/Missing", "specmill://operations/missing", "https://example.com"} {
+		if _, err := gen.ReadResource(context.Background(), uri); !errors.Is(err, ErrResourceNotFound) {
+			t.Errorf("Expected ErrResourceNotFound for %s, got: %v", uri, err)
+		}
+	}
+}
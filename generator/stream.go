@@ -0,0 +1,251 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"specmill/parser"
+)
+
+const (
+	defaultStreamMaxEvents   = 1000
+	defaultStreamMaxDuration = 5 * time.Minute
+)
+
+// LogFunc receives log messages emitted while a tool call runs, such as the
+// events of a streaming response. logger names the tool.
+type LogFunc func(level, logger string, data any)
+
+type logKey struct{}
+
+// WithLog returns a context under which ExecuteTool forwards the events of
+// streaming upstream responses to log.
+func WithLog(ctx context.Context, log LogFunc) context.Context {
+	return context.WithValue(ctx, logKey{}, log)
+}
+
+func logFromContext(ctx context.Context) LogFunc {
+	log, _ := ctx.Value(logKey{}).(LogFunc)
+	return log
+}
+
+// streamEvent is one event of a streaming response. NDJSON lines only have
+// Data, which holds the decoded JSON value.
+type streamEvent struct {
+	Event string `json:"event,omitempty"`
+	ID    string `json:"id,omitempty"`
+	Data  any    `json:"data"`
+}
+
+// streamFormat returns "sse" or "ndjson" for streaming media types and ""
+// otherwise.
+func streamFormat(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "text/event-stream":
+		return "sse"
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines", "application/json-seq":
+		return "ndjson"
+	}
+	return ""
+}
+
+// streamLimits returns the limits for an operation's streaming responses:
+// the operation's config, else its x-specmill extension, else the global
+// config, with defaults for unset fields.
+func (g *MCPGenerator) streamLimits(op *parser.Operation) (StreamingConfig, error) {
+	limits := g.config.Streaming
+	if cfg := g.config.Operations[op.OperationID].Streaming; cfg != nil {
+		limits = *cfg
+	} else {
+		extension, err := operationExtension(op.Specmill)
+		if err != nil {
+			return limits, fmt.Errorf("invalid x-specmill extension for %s: %w", op.OperationID, err)
+		}
+		if extension.Streaming != nil {
+			limits = *extension.Streaming
+		}
+	}
+
+	if limits.MaxEvents <= 0 {
+		limits.MaxEvents = defaultStreamMaxEvents
+	}
+	if limits.MaxDuration <= 0 {
+		limits.MaxDuration = defaultStreamMaxDuration
+	}
+	return limits, nil
+}
+
+// readStream consumes a streaming response incrementally, forwarding each
+// event as a log message and a progress update, until the upstream closes
+// the stream or a limit is reached. The events are aggregated into the
+// result along with metadata describing why reading stopped.
+func (g *MCPGenerator) readStream(ctx context.Context, name string, limits StreamingConfig, resp *http.Response) (*CallToolResult, map[string]any, error) {
+	defer resp.Body.Close()
+
+	var expired atomic.Bool
+	timer := time.AfterFunc(limits.MaxDuration, func() {
+		expired.Store(true)
+		resp.Body.Close()
+	})
+	defer timer.Stop()
+
+	log := logFromContext(ctx)
+	tracker := progressFromContext(ctx)
+
+	var events []streamEvent
+	reason := ""
+	emit := func(event streamEvent) bool {
+		events = append(events, event)
+		if log != nil {
+			log("info", name, event)
+		}
+		tracker.advanceSteps(1, fmt.Sprintf("Received %d events", len(events)), false)
+		if len(events) >= limits.MaxEvents {
+			reason = "maxEvents"
+			return false
+		}
+		return true
+	}
+
+	// One byte past the cap is read so that a stream longer than the cap
+	// can be told apart from one ending exactly at it.
+	var err error
+	body := &io.LimitedReader{R: resp.Body, N: maxResponseBytes + 1}
+	if streamFormat(resp.Header.Get("Content-Type")) == "sse" {
+		err = scanSSE(body, emit)
+	} else {
+		err = scanNDJSON(body, emit)
+	}
+
+	switch {
+	case expired.Load():
+		reason = "maxDuration"
+	case ctx.Err() != nil:
+		return nil, nil, ctx.Err()
+	case err != nil && reason == "":
+		return nil, nil, fmt.Errorf("failed to read stream: %w", err)
+	case cutOff(body) && reason == "":
+		reason = "maxBytes"
+	}
+	tracker.advanceSteps(0, fmt.Sprintf("Received %d events", len(events)), true)
+
+	if events == nil {
+		events = []streamEvent{}
+	}
+	data, err := json.Marshal(events)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode stream events: %w", err)
+	}
+
+	text := string(data)
+	meta := map[string]any{"events": len(events)}
+	if reason != "" {
+		meta["truncated"] = reason
+		text = fmt.Sprintf("Stream stopped after %d events (%s limit reached):\n%s", len(events), reason, text)
+	}
+
+	return &CallToolResult{Content: []ToolContent{{Type: "text", Text: text}}}, meta, nil
+}
+
+// scanSSE parses a text/event-stream body, calling emit for each event
+// until it returns false.
+func scanSSE(r io.Reader, emit func(streamEvent) bool) error {
+	scanner := newLineScanner(r)
+
+	var event streamEvent
+	var data []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			if data != nil {
+				event.Data = decodeEventData(strings.Join(data, "\n"))
+				if !emit(event) {
+					return nil
+				}
+			}
+			event, data = streamEvent{}, nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "id":
+			event.ID = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if data != nil && !cutOff(r) {
+		event.Data = decodeEventData(strings.Join(data, "\n"))
+		emit(event)
+	}
+	return nil
+}
+
+// scanNDJSON parses a newline-delimited JSON body, calling emit for each
+// line until it returns false.
+func scanNDJSON(r io.Reader, emit func(streamEvent) bool) error {
+	scanner := newLineScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\x1e"))
+		if line == "" {
+			continue
+		}
+		if !emit(streamEvent{Data: decodeEventData(line)}) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// newLineScanner splits r into lines, dropping a final line cut off by the
+// limit of a LimitedReader since it is incomplete.
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxResponseBytes)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && cutOff(r) && bytes.IndexByte(data, '\n') < 0 {
+			return len(data), nil, nil
+		}
+		return bufio.ScanLines(data, atEOF)
+	})
+	return scanner
+}
+
+// cutOff reports whether r is a LimitedReader that stopped at its limit.
+func cutOff(r io.Reader) bool {
+	limited, ok := r.(*io.LimitedReader)
+	return ok && limited.N <= 0
+}
+
+// decodeEventData returns event data as a JSON value when it parses as one
+// and as a string otherwise.
+func decodeEventData(data string) any {
+	var value any
+	if err := json.Unmarshal([]byte(data), &value); err == nil {
+		return value
+	}
+	return data
+}
@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"specmill/parser"
+)
+
+func TestStreamFormat(t *testing.T) {
+	tests := []struct {
+		contentType string
+		expected    string
+	}{
+		{"text/event-stream", "sse"},
+		{"text/event-stream; charset=utf-8", "sse"},
+		{"application/x-ndjson", "ndjson"},
+		{"application/jsonl", "ndjson"},
+		{"application/json", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := streamFormat(tt.contentType); got != tt.expected {
+			t.Errorf("Expected %q for %q, got: %q", tt.expected, tt.contentType, got)
+		}
+	}
+}
+
+func TestExecuteToolStream(t *testing.T) {
+	padded := `{"pad":"` + strings.Repeat("x", 60000) + `"}` + "\n"
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		hang        bool
+		limits      *StreamingConfig
+		expected    []string
+		events      int
+		truncated   string
+	}{
+		{
+			name:        "Server-sent events",
+			contentType: "text/event-stream",
+			body:        ": keep-alive\n\nevent: token\nid: 1\ndata: {\"text\":\"Hel\"}\n\ndata: lo\ndata: world\n\n",
+			expected:    []string{`{"event":"token","id":"1","data":{"text":"Hel"}}`, `{"data":"lo\nworld"}`},
+			events:      2,
+		},
+		{
+			name:        "NDJSON",
+			contentType: "application/x-ndjson",
+			body:        "{\"n\":1}\n\n{\"n\":2}\n",
+			expected:    []string{`{"data":{"n":1}}`, `{"data":{"n":2}}`},
+			events:      2,
+		},
+		{
+			name:        "Event limit",
+			contentType: "application/x-ndjson",
+			body:        "{\"n\":1}\n{\"n\":2}\n{\"n\":3}\n",
+			limits:      &StreamingConfig{MaxEvents: 2},
+			expected:    []string{"maxEvents limit reached", `{"data":{"n":2}}`},
+			events:      2,
+			truncated:   "maxEvents",
+		},
+		{
+			name:        "Duration limit",
+			contentType: "text/event-stream",
+			body:        "data: first\n\n",
+			hang:        true,
+			limits:      &StreamingConfig{MaxDuration: 50 * time.Millisecond},
+			expected:    []string{"maxDuration limit reached", `{"data":"first"}`},
+			events:      1,
+			truncated:   "maxDuration",
+		},
+		{
+			name:        "Byte limit",
+			contentType: "application/x-ndjson",
+			body:        strings.Repeat(padded, maxResponseBytes/len(padded)+10),
+			expected:    []string{"maxBytes limit reached"},
+			events:      maxResponseBytes / len(padded),
+			truncated:   "maxBytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write([]byte(tt.body))
+				w.(http.Flusher).Flush()
+				if tt.hang {
+					select {
+					case <-r.Context().Done():
+					case <-release:
+					}
+				}
+			}))
+			defer upstream.Close()
+			defer close(release)
+
+			spec := &parser.OpenAPISpec{
+				Servers: []parser.Server{{URL: upstream.URL}},
+				Paths: map[string]parser.PathItem{
+					"/events": {Get: &parser.Operation{OperationID: "watchEvents"}},
+				},
+			}
+			cfg := &Config{}
+			if tt.limits != nil {
+				cfg.Operations = map[string]OperationConfig{"watchEvents": {Streaming: tt.limits}}
+			}
+			gen := NewMCPGeneratorWithConfig(spec, cfg)
+			if err := gen.GenerateTools(); err != nil {
+				t.Fatalf("Failed to generate tools: %v", err)
+			}
+
+			var logged []any
+			ctx := WithLog(context.Background(), func(level, logger string, data any) {
+				if level != "info" || logger != "watchEvents" {
+					t.Errorf("Unexpected log level %s or logger %s", level, logger)
+				}
+				logged = append(logged, data)
+			})
+
+			result, err := gen.ExecuteTool(ctx, "watchEvents", nil)
+			if err != nil {
+				t.Fatalf("Failed to execute tool: %v", err)
+			}
+			if result.IsError {
+				t.Errorf("Expected success, got: %s", result.Content[0].Text)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(result.Content[0].Text, expected) {
+					t.Errorf("Expected result to contain %s, got: %s", expected, result.Content[0].Text)
+				}
+			}
+			if len(logged) != tt.events {
+				t.Errorf("Expected %d logged events, got: %d", tt.events, len(logged))
+			}
+
+			stream, _ := result.Meta["specmill/stream"].(map[string]any)
+			if stream["events"] != tt.events {
+				t.Errorf("Expected %d events in metadata, got: %v", tt.events, stream)
+			}
+			if truncated, _ := stream["truncated"].(string); truncated != tt.truncated {
+				t.Errorf("Expected truncated %q, got: %q", tt.truncated, truncated)
+			}
+		})
+	}
+}
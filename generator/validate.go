@@ -0,0 +1,271 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) String() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// validateArguments checks args against a tool's generated input schema and
+// returns every violation found, rooted at "$".
+func validateArguments(schema map[string]interface{}, args map[string]interface{}) []ValidationError {
+	return validateValue(schema, args, "$")
+}
+
+func validateValue(schema map[string]interface{}, value interface{}, path string) []ValidationError {
+	if schema == nil {
+		return nil
+	}
+
+	var errs []ValidationError
+
+	schemaType, _ := schema["type"].(string)
+	if !matchesType(schema, value) {
+		return append(errs, ValidationError{
+			Path:    path,
+			Message: fmt.Sprintf("expected %s, got %s", schemaType, jsonTypeName(value)),
+		})
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 && !enumContains(enum, value) {
+		errs = append(errs, ValidationError{
+			Path:    path,
+			Message: fmt.Sprintf("must be one of %s", formatEnum(enum)),
+		})
+	}
+
+	switch v := value.(type) {
+	case float64:
+		errs = append(errs, validateNumber(schema, v, path)...)
+	case string:
+		errs = append(errs, validateString(schema, v, path)...)
+	case []interface{}:
+		errs = append(errs, validateArray(schema, v, path)...)
+	case map[string]interface{}:
+		errs = append(errs, validateObject(schema, v, path)...)
+	}
+
+	return errs
+}
+
+func validateNumber(schema map[string]interface{}, v float64, path string) []ValidationError {
+	var errs []ValidationError
+
+	if min, ok := schema["minimum"].(float64); ok && v < min {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must be >= %v", min)})
+	}
+	if max, ok := schema["maximum"].(float64); ok && v > max {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must be <= %v", max)})
+	}
+	if min, ok := schema["exclusiveMinimum"].(float64); ok && v <= min {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must be > %v", min)})
+	}
+	if max, ok := schema["exclusiveMaximum"].(float64); ok && v >= max {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must be < %v", max)})
+	}
+
+	return errs
+}
+
+func validateString(schema map[string]interface{}, v string, path string) []ValidationError {
+	var errs []ValidationError
+
+	length := utf8.RuneCountInString(v)
+	if min, ok := schema["minLength"].(float64); ok && float64(length) < min {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("length must be >= %v", min)})
+	}
+	if max, ok := schema["maxLength"].(float64); ok && float64(length) > max {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("length must be <= %v", max)})
+	}
+	if pattern, ok := schema["pattern"].(string); ok && pattern != "" {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+			errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must match pattern %q", pattern)})
+		}
+	}
+
+	return errs
+}
+
+func validateArray(schema map[string]interface{}, v []interface{}, path string) []ValidationError {
+	var errs []ValidationError
+
+	if min, ok := schema["minItems"].(float64); ok && float64(len(v)) < min {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must have at least %v items", min)})
+	}
+	if max, ok := schema["maxItems"].(float64); ok && float64(len(v)) > max {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must have at most %v items", max)})
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		seen := make(map[string]bool)
+		for _, item := range v {
+			key, _ := json.Marshal(item)
+			if seen[string(key)] {
+				errs = append(errs, ValidationError{Path: path, Message: "items must be unique"})
+				break
+			}
+			seen[string(key)] = true
+		}
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range v {
+			errs = append(errs, validateValue(items, item, indexPath(path, i))...)
+		}
+	}
+
+	return errs
+}
+
+func validateObject(schema map[string]interface{}, v map[string]interface{}, path string) []ValidationError {
+	var errs []ValidationError
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, present := v[name]; !present {
+				errs = append(errs, ValidationError{Path: childPath(path, name), Message: "is required"})
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propSchema, ok := properties[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		errs = append(errs, validateValue(propSchema, v[name], childPath(path, name))...)
+	}
+
+	return errs
+}
+
+// matchesType reports whether value has the type declared by schema. null
+// is also accepted for schemas marked nullable, as in OpenAPI 3.0.
+func matchesType(schema map[string]interface{}, value interface{}) bool {
+	if nullable, _ := schema["nullable"].(bool); nullable && value == nil {
+		return true
+	}
+
+	schemaType, _ := schema["type"].(string)
+	switch schemaType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func jsonTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	key, _ := json.Marshal(value)
+	for _, e := range enum {
+		candidate, _ := json.Marshal(e)
+		if string(candidate) == string(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, e := range enum {
+		data, _ := json.Marshal(e)
+		values[i] = string(data)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+func childPath(path, name string) string {
+	if isIdentifier(name) {
+		return path + "." + name
+	}
+	quoted, _ := json.Marshal(name)
+	return fmt.Sprintf("%s[%s]", path, quoted)
+}
+
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '$':
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func formatValidationErrors(errs []ValidationError) string {
+	lines := make([]string, 0, len(errs)+1)
+	lines = append(lines, "Invalid arguments:")
+	for _, e := range errs {
+		lines = append(lines, "- "+e.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,240 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"specmill/parser"
+)
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestValidateArguments(t *testing.T) {
+	schema := map[string]interface{}{}
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"limit": {"type": "integer", "minimum": 1, "maximum": 100},
+			"status": {"type": "string", "enum": ["available", "pending", "sold"]},
+			"code": {"type": "string", "pattern": "^[A-Z]{3}$", "maxLength": 3},
+			"body": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string", "minLength": 1},
+					"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+					"photo urls": {"type": "array", "uniqueItems": true}
+				},
+				"required": ["name"]
+			}
+		},
+		"required": ["limit"]
+	}`), &schema)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     string
+		expected []string
+	}{
+		{
+			name:     "Valid arguments",
+			args:     `{"limit": 10, "status": "sold", "code": "ABC", "body": {"name": "Rex", "tags": ["a"]}}`,
+			expected: nil,
+		},
+		{
+			name:     "Missing required",
+			args:     `{}`,
+			expected: []string{"$.limit: is required"},
+		},
+		{
+			name:     "Wrong type",
+			args:     `{"limit": "10"}`,
+			expected: []string{"$.limit: expected integer, got string"},
+		},
+		{
+			name:     "Fractional integer",
+			args:     `{"limit": 1.5}`,
+			expected: []string{"$.limit: expected integer, got number"},
+		},
+		{
+			name:     "Bounds",
+			args:     `{"limit": 0}`,
+			expected: []string{"$.limit: must be >= 1"},
+		},
+		{
+			name:     "Enum",
+			args:     `{"limit": 1, "status": "lost"}`,
+			expected: []string{`$.status: must be one of ["available", "pending", "sold"]`},
+		},
+		{
+			name:     "Pattern and length",
+			args:     `{"limit": 1, "code": "abcd"}`,
+			expected: []string{"$.code: length must be <= 3", `$.code: must match pattern "^[A-Z]{3}$"`},
+		},
+		{
+			name: "Nested body",
+			args: `{"limit": 1, "body": {"tags": ["a", 2, "c"], "photo urls": ["x", "x"]}}`,
+			expected: []string{
+				"$.body.name: is required",
+				`$.body["photo urls"]: items must be unique`,
+				"$.body.tags: must have at most 2 items",
+				"$.body.tags[1]: expected string, got integer",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.args), &args); err != nil {
+				t.Fatalf("Failed to parse args: %v", err)
+			}
+
+			errs := validateArguments(schema, args)
+			if len(errs) != len(tt.expected) {
+				t.Fatalf("Expected %d errors, got: %v", len(tt.expected), errs)
+			}
+			for i, expected := range tt.expected {
+				if errs[i].String() != expected {
+					t.Errorf("Expected error '%s', got: '%s'", expected, errs[i].String())
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteToolValidation(t *testing.T) {
+	called := false
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	spec := &parser.OpenAPISpec{
+		Servers: []parser.Server{{URL: upstream.URL}},
+		Paths: map[string]parser.PathItem{
+			"/pets/{petId}": {
+				Get: &parser.Operation{
+					OperationID: "getPet",
+					Parameters: []parser.Parameter{
+						{
+							Name:     "petId",
+							In:       "path",
+							Required: true,
+							Schema:   &parser.Schema{Type: "integer", Minimum: floatPtr(1)},
+						},
+						{
+							Name:   "fields",
+							In:     "query",
+							Schema: &parser.Schema{Type: "string", MaxLength: intPtr(5)},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	gen := NewMCPGenerator(spec)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+
+	result, err := gen.ExecuteTool(context.Background(), "getPet", json.RawMessage(`{"petId": 0, "fields": "name,tag"}`))
+	if err != nil {
+		t.Fatalf("Expected validation result, got error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected isError result")
+	}
+	if called {
+		t.Error("Upstream should not be called with invalid arguments")
+	}
+	text := result.Content[0].Text
+	if !strings.Contains(text, "$.petId: must be >= 1") || !strings.Contains(text, "$.fields: length must be <= 5") {
+		t.Errorf("Expected every violation to be listed, got: %s", text)
+	}
+
+	result, err = gen.ExecuteTool(context.Background(), "getPet", json.RawMessage(`{"petId": 7}`))
+	if err != nil {
+		t.Fatalf("Failed to execute tool: %v", err)
+	}
+	if result.IsError {
+		t.Errorf("Expected valid call to succeed, got: %s", result.Content[0].Text)
+	}
+	if !called {
+		t.Error("Upstream should be called with valid arguments")
+	}
+}
+
+func TestValidateSpecKeywords(t *testing.T) {
+	spec, err := parser.ParseOpenAPISpecData([]byte(`openapi: 3.1.0
+info: {title: Pets, version: "1.0"}
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      parameters:
+        - name: weight
+          in: query
+          schema: {type: number, exclusiveMinimum: 0}
+        - name: age
+          in: query
+          schema: {type: integer, maximum: 30, exclusiveMaximum: true}
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                tag: {type: string, nullable: true}
+                name: {type: string}
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	gen := NewMCPGenerator(spec)
+	if err := gen.GenerateTools(); err != nil {
+		t.Fatalf("Failed to generate tools: %v", err)
+	}
+	ref := gen.operations["createPet"]
+
+	tests := []struct {
+		args     string
+		expected []string
+	}{
+		{args: `{"weight": 2, "age": 29, "body": {"tag": null, "name": "Rex"}}`},
+		{args: `{"weight": 0, "age": 30}`, expected: []string{"$.age: must be < 30", "$.weight: must be > 0"}},
+		{args: `{"body": {"name": null}}`, expected: []string{"$.body.name: expected string, got null"}},
+	}
+
+	for _, tt := range tests {
+		var args map[string]interface{}
+		if err := json.Unmarshal([]byte(tt.args), &args); err != nil {
+			t.Fatalf("Failed to parse args: %v", err)
+		}
+
+		errs := validateArguments(ref.inputSchema, args)
+		if len(errs) != len(tt.expected) {
+			t.Fatalf("Expected %d errors for %s, got: %v", len(tt.expected), tt.args, errs)
+		}
+		for i, expected := range tt.expected {
+			if errs[i].String() != expected {
+				t.Errorf("Expected error '%s', got: '%s'", expected, errs[i].String())
+			}
+		}
+	}
+}
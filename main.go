@@ -1,17 +1,39 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"specmill/generator"
 	"specmill/server"
 )
 
 func main() {
 	var specPath string
-	flag.StringVar(&specPath, "spec", "", "Path to OpenAPI spec file (YAML)")
+	var configPath string
+	var transport string
+	var listenAddr string
+	var maxMessageSize int
+	var shutdownTimeout time.Duration
+	var reloadInterval time.Duration
+	var pageSize int
+	var sessionIdle time.Duration
+	flag.StringVar(&specPath, "spec", "", "Path or http(s) URL of the OpenAPI spec (YAML or JSON)")
+	flag.StringVar(&configPath, "config", "", "Path to specmill config file (YAML)")
+	flag.StringVar(&transport, "transport", "stdio", "Transport to serve MCP over (stdio or http)")
+	flag.StringVar(&listenAddr, "listen", ":8080", "Address to listen on for the http transport")
+	flag.IntVar(&maxMessageSize, "max-message-size", 16<<20, "Maximum size in bytes of a single JSON-RPC message")
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", 10*time.Second, "How long in-flight requests may run after SIGINT or SIGTERM")
+	flag.DurationVar(&reloadInterval, "reload", 0, "Re-read the spec at this interval and notify clients of changes (0 disables)")
+	flag.IntVar(&pageSize, "page-size", 0, "Maximum number of tools, resources or prompts per list response (0 disables pagination)")
+	flag.DurationVar(&sessionIdle, "session-idle-timeout", 30*time.Minute, "End http sessions idle for this long")
 	flag.Parse()
 
 	if specPath == "" {
@@ -20,12 +42,51 @@ func main() {
 		os.Exit(1)
 	}
 
-	srv, err := server.NewMCPServer(specPath)
+	cfg := &generator.Config{}
+	if configPath != "" {
+		var err error
+		cfg, err = generator.LoadConfig(configPath)
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+	}
+
+	srv, err := server.NewMCPServer(specPath,
+		server.WithConfig(cfg),
+		server.WithLogger(log.New(os.Stderr, "specmill: ", log.LstdFlags)),
+		server.WithMaxMessageSize(maxMessageSize),
+		server.WithPageSize(pageSize),
+		server.WithShutdownTimeout(shutdownTimeout),
+		server.WithSessionIdleTimeout(sessionIdle),
+	)
 	if err != nil {
 		log.Fatalf("Failed to create MCP server: %v", err)
 	}
 
-	if err := srv.Start(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if reloadInterval > 0 {
+		go srv.Watch(ctx, reloadInterval)
+	}
+
+	switch transport {
+	case "stdio":
+		err = srv.Serve(ctx, os.Stdin, os.Stdout)
+	case "http":
+		log.Printf("Serving MCP over HTTP on %s/mcp", listenAddr)
+		err = srv.ListenAndServe(ctx, listenAddr)
+	default:
+		log.Fatalf("Unknown transport: %s", transport)
+	}
+
+	switch {
+	case err == nil || errors.Is(err, context.Canceled):
+		// Clean exit on EOF or after a signal once requests have drained.
+	case errors.Is(err, server.ErrShutdownTimeout):
+		log.Printf("Shutdown incomplete: %v", err)
+		os.Exit(1)
+	default:
 		log.Fatalf("Server error: %v", err)
 	}
-}
\ No newline at end of file
+}
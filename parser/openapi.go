@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"os"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,6 +14,12 @@ type OpenAPISpec struct {
 	Servers []Server                   `yaml:"servers"`
 	Paths   map[string]PathItem        `yaml:"paths"`
 	Components *Components             `yaml:"components,omitempty"`
+	Tags       []Tag                   `yaml:"tags,omitempty"`
+}
+
+type Tag struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
 }
 
 type Info struct {
@@ -44,6 +51,12 @@ type Operation struct {
 	RequestBody *RequestBody           `yaml:"requestBody,omitempty"`
 	Responses   map[string]Response    `yaml:"responses"`
 	Tags        []string               `yaml:"tags,omitempty"`
+	// Specmill holds the x-specmill extension, which accepts the same
+	// settings as an operation entry of the specmill config.
+	Specmill map[string]interface{} `yaml:"x-specmill,omitempty"`
+	// SpecmillPagination holds the x-specmill-pagination extension, a
+	// shorthand for the pagination entry of x-specmill.
+	SpecmillPagination map[string]interface{} `yaml:"x-specmill-pagination,omitempty"`
 }
 
 type Parameter struct {
@@ -70,7 +83,7 @@ type Response struct {
 }
 
 type Schema struct {
-	Type        string              `yaml:"type"`
+	Type        string              `yaml:"type,omitempty"`
 	Format      string              `yaml:"format,omitempty"`
 	Properties  map[string]*Schema  `yaml:"properties,omitempty"`
 	Items       *Schema             `yaml:"items,omitempty"`
@@ -78,6 +91,54 @@ type Schema struct {
 	Ref         string              `yaml:"$ref,omitempty"`
 	Enum        []interface{}       `yaml:"enum,omitempty"`
 	Description string              `yaml:"description,omitempty"`
+	Default     interface{}         `yaml:"default,omitempty"`
+	Nullable    bool                `yaml:"nullable,omitempty"`
+
+	Minimum          *float64       `yaml:"minimum,omitempty"`
+	Maximum          *float64       `yaml:"maximum,omitempty"`
+	ExclusiveMinimum ExclusiveBound `yaml:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum ExclusiveBound `yaml:"exclusiveMaximum,omitempty"`
+	MinLength        *int           `yaml:"minLength,omitempty"`
+	MaxLength        *int           `yaml:"maxLength,omitempty"`
+	Pattern          string         `yaml:"pattern,omitempty"`
+	MinItems         *int           `yaml:"minItems,omitempty"`
+	MaxItems         *int           `yaml:"maxItems,omitempty"`
+	UniqueItems      bool           `yaml:"uniqueItems,omitempty"`
+}
+
+// ExclusiveBound holds exclusiveMinimum or exclusiveMaximum. OpenAPI 3.0
+// writes them as booleans that make minimum or maximum exclusive, while 3.1
+// follows JSON Schema and writes the exclusive bound itself.
+type ExclusiveBound struct {
+	// Exclusive is the 3.0 form.
+	Exclusive bool
+	// Value is the 3.1 form.
+	Value *float64
+}
+
+func (b *ExclusiveBound) UnmarshalYAML(node *yaml.Node) error {
+	if node.Tag == "!!bool" {
+		*b = ExclusiveBound{}
+		return node.Decode(&b.Exclusive)
+	}
+
+	var value float64
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+	*b = ExclusiveBound{Value: &value}
+	return nil
+}
+
+func (b ExclusiveBound) MarshalYAML() (interface{}, error) {
+	if b.Value != nil {
+		return *b.Value, nil
+	}
+	return b.Exclusive, nil
+}
+
+func (b ExclusiveBound) IsZero() bool {
+	return !b.Exclusive && b.Value == nil
 }
 
 type Components struct {
@@ -90,8 +151,14 @@ func ParseOpenAPISpec(filePath string) (*OpenAPISpec, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	return ParseOpenAPISpecData(data)
+}
+
+// ParseOpenAPISpecData parses a spec held in memory. JSON specs are accepted
+// as well, since JSON is a subset of YAML.
+func ParseOpenAPISpecData(data []byte) (*OpenAPISpec, error) {
 	var spec OpenAPISpec
-	err = yaml.Unmarshal(data, &spec)
+	err := yaml.Unmarshal(data, &spec)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
@@ -99,6 +166,10 @@ func ParseOpenAPISpec(filePath string) (*OpenAPISpec, error) {
 	return &spec, nil
 }
 
+// Methods lists the operation fields of a PathItem in the order the OpenAPI
+// specification defines them.
+var Methods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
 func (p *PathItem) GetOperations() map[string]*Operation {
 	ops := make(map[string]*Operation)
 	if p.Get != nil {
@@ -123,4 +194,15 @@ func (p *PathItem) GetOperations() map[string]*Operation {
 		ops["head"] = p.Head
 	}
 	return ops
-}
\ No newline at end of file
+}
+
+// SortedPaths returns the spec's paths in lexical order so that callers
+// iterating over them produce stable output.
+func (s *OpenAPISpec) SortedPaths() []string {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -107,4 +107,78 @@ func TestParseInvalidYAML(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error for invalid YAML")
 	}
-}
\ No newline at end of file
+}
+func TestSortedPaths(t *testing.T) {
+	spec := &OpenAPISpec{
+		Paths: map[string]PathItem{
+			"/user":        {},
+			"/pet/{petId}": {},
+			"/pet":         {},
+			"/store/order": {},
+		},
+	}
+
+	expected := []string{"/pet", "/pet/{petId}", "/store/order", "/user"}
+	paths := spec.SortedPaths()
+	if len(paths) != len(expected) {
+		t.Fatalf("Expected %d paths, got: %d", len(expected), len(paths))
+	}
+	for i, path := range expected {
+		if paths[i] != path {
+			t.Errorf("Expected path %d to be %s, got: %s", i, path, paths[i])
+		}
+	}
+}
+
+func TestParseOpenAPISpecData(t *testing.T) {
+	data := []byte(`{"openapi": "3.0.0", "info": {"title": "JSON API", "version": "1.0"}, "paths": {"/ping": {"get": {"operationId": "ping"}}}}`)
+
+	spec, err := ParseOpenAPISpecData(data)
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	if spec.Info.Title != "JSON API" {
+		t.Errorf("Expected title 'JSON API', got: %s", spec.Info.Title)
+	}
+
+	if spec.Paths["/ping"].Get == nil {
+		t.Error("Expected GET /ping operation")
+	}
+}
+
+func TestParseExclusiveBounds(t *testing.T) {
+	data := []byte(`openapi: 3.1.0
+info: {title: Bounds, version: "1.0"}
+paths: {}
+components:
+  schemas:
+    Legacy:
+      type: number
+      minimum: 0
+      exclusiveMinimum: true
+      nullable: true
+    Current:
+      type: number
+      exclusiveMinimum: 0
+      exclusiveMaximum: 1.5
+`)
+
+	spec, err := ParseOpenAPISpecData(data)
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	legacy := spec.Components.Schemas["Legacy"]
+	if !legacy.ExclusiveMinimum.Exclusive || legacy.ExclusiveMinimum.Value != nil || !legacy.Nullable {
+		t.Errorf("Expected boolean exclusiveMinimum and nullable, got: %+v", legacy)
+	}
+
+	current := spec.Components.Schemas["Current"]
+	if current.ExclusiveMinimum.Value == nil || *current.ExclusiveMinimum.Value != 0 {
+		t.Errorf("Expected numeric exclusiveMinimum 0, got: %+v", current.ExclusiveMinimum)
+	}
+	if current.ExclusiveMaximum.Value == nil || *current.ExclusiveMaximum.Value != 1.5 {
+		t.Errorf("Expected numeric exclusiveMaximum 1.5, got: %+v", current.ExclusiveMaximum)
+	}
+}
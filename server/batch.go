@@ -0,0 +1,81 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+
+	"specmill/generator"
+)
+
+// batchProtocolVersions lists the protocol versions that allow JSON-RPC
+// batches. Batching was introduced in 2025-03-26 and removed in 2025-06-18.
+var batchProtocolVersions = map[string]bool{"2025-03-26": true}
+
+func isBatch(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// handleBatch processes a JSON-RPC batch. Notifications are handled in order
+// first, then requests run concurrently. It returns the responses in request
+// order, none if the batch only held notifications, or a single error
+// response when the batch as a whole is invalid.
+func (s *MCPServer) handleBatch(ctx context.Context, sess *session, data []byte) ([]*generator.MCPResponse, *generator.MCPResponse) {
+	var messages []json.RawMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, errorResponse(nil, codeParseError, "Parse error")
+	}
+
+	if len(messages) == 0 {
+		return nil, errorResponse(nil, codeInvalidRequest, "Invalid Request: empty batch")
+	}
+
+	if !batchProtocolVersions[sess.getProtocolVersion()] {
+		return nil, errorResponse(nil, codeInvalidRequest, "Invalid Request: batches are not supported by the negotiated protocol version")
+	}
+
+	responses := make([]*generator.MCPResponse, len(messages))
+	var requests []int
+	parsed := make([]generator.MCPRequest, len(messages))
+
+	for i, message := range messages {
+		if errResp := decodeRequest(message, &parsed[i]); errResp != nil {
+			responses[i] = errResp
+			continue
+		}
+
+		switch {
+		case parsed[i].Method == "initialize":
+			responses[i] = errorResponse(parsed[i].ID, codeInvalidRequest, "Invalid Request: initialize must not be part of a batch")
+		case parsed[i].ID == nil:
+			s.handleMessage(ctx, sess, &parsed[i])
+		default:
+			requests = append(requests, i)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, i := range requests {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := s.acquireSlot(ctx); err != nil {
+				responses[i] = errorResponse(parsed[i].ID, codeInternalError, err.Error())
+				return
+			}
+			defer s.releaseSlot()
+			responses[i] = s.handleMessage(ctx, sess, &parsed[i])
+		}(i)
+	}
+	wg.Wait()
+
+	replies := make([]*generator.MCPResponse, 0, len(responses))
+	for _, response := range responses {
+		if response != nil {
+			replies = append(replies, response)
+		}
+	}
+	return replies, nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"specmill/generator"
+)
+
+func TestHandleBatch(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	sess := newSession()
+	sess.setProtocolVersion("2025-03-26")
+	sess.setState(stateReady)
+
+	batch := `[
+		{"jsonrpc":"2.0","method":"tools/list","id":1},
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","method":"ping","id":"b"},
+		5,
+		{"jsonrpc":"2.0","method":"initialize","params":{},"id":3},
+		{"jsonrpc":"2.0","method":"unknown","id":4}
+	]`
+
+	responses, errResp := srv.handleBatch(context.Background(), sess, []byte(batch))
+	if errResp != nil {
+		t.Fatalf("Expected batch to be accepted, got: %+v", errResp)
+	}
+
+	expected := []struct {
+		id   interface{}
+		code int
+	}{
+		{id: float64(1)},
+		{id: "b"},
+		{id: nil, code: codeInvalidRequest},
+		{id: float64(3), code: codeInvalidRequest},
+		{id: float64(4), code: codeMethodNotFound},
+	}
+
+	if len(responses) != len(expected) {
+		t.Fatalf("Expected %d responses, got: %d", len(expected), len(responses))
+	}
+	for i, exp := range expected {
+		response := responses[i]
+		if response.ID != exp.id {
+			t.Errorf("Response %d: expected id %v, got: %v", i, exp.id, response.ID)
+		}
+		switch {
+		case exp.code == 0 && response.Error != nil:
+			t.Errorf("Response %d: unexpected error: %+v", i, response.Error)
+		case exp.code != 0 && (response.Error == nil || response.Error.Code != exp.code):
+			t.Errorf("Response %d: expected error %d, got: %+v", i, exp.code, response.Error)
+		}
+	}
+
+	responses, errResp = srv.handleBatch(context.Background(), sess, []byte(`[{"jsonrpc":"2.0","method":"notifications/initialized"}]`))
+	if errResp != nil || len(responses) != 0 {
+		t.Errorf("Expected no responses for notification-only batch, got: %v %+v", responses, errResp)
+	}
+
+	_, errResp = srv.handleBatch(context.Background(), sess, []byte(`[]`))
+	if errResp == nil || errResp.Error.Code != codeInvalidRequest {
+		t.Errorf("Expected invalid request for empty batch, got: %+v", errResp)
+	}
+
+	sess.setProtocolVersion("2025-06-18")
+	_, errResp = srv.handleBatch(context.Background(), sess, []byte(`[{"jsonrpc":"2.0","method":"ping","id":1}]`))
+	if errResp == nil || errResp.Error.Code != codeInvalidRequest {
+		t.Errorf("Expected batches to be rejected for 2025-06-18, got: %+v", errResp)
+	}
+}
+
+func TestHTTPBatch(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.HTTPHandler())
+	defer ts.Close()
+
+	resp := postMCP(t, ts.URL, "", "application/json", `{"jsonrpc":"2.0","method":"initialize","params":{"protocolVersion":"2025-03-26"},"id":1}`)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(sessionHeader)
+
+	resp = postMCP(t, ts.URL, sessionID, "application/json", `[{"jsonrpc":"2.0","method":"ping","id":2},{"jsonrpc":"2.0","method":"ping","id":3}]`)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 for batch, got: %d", resp.StatusCode)
+	}
+
+	var responses []generator.MCPResponse
+	if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		t.Fatalf("Failed to decode batch response: %v", err)
+	}
+	if len(responses) != 2 || responses[0].ID != float64(2) || responses[1].ID != float64(3) {
+		t.Errorf("Expected responses for ids 2 and 3, got: %+v", responses)
+	}
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"specmill/generator"
+)
+
+func (s *MCPServer) handleComplete(ctx context.Context, request *generator.MCPRequest) *generator.MCPResponse {
+	var params generator.CompleteParams
+	if err := json.Unmarshal(request.Params, &params); err != nil || params.Argument.Name == "" {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params")
+	}
+
+	result, err := s.generator.Load().Complete(ctx, params.Ref, params.Argument)
+	if errors.Is(err, generator.ErrInvalidCompletionReference) {
+		return errorResponse(request.ID, codeInvalidParams, err.Error())
+	}
+	if err != nil {
+		s.logf("completion of %s failed: %v", params.Argument.Name, err)
+		return errorResponse(request.ID, codeInternalError, err.Error())
+	}
+
+	resultBytes, _ := json.Marshal(result)
+	return &generator.MCPResponse{
+		Jsonrpc: "2.0",
+		Result:  json.RawMessage(resultBytes),
+		ID:      request.ID,
+	}
+}
@@ -0,0 +1,442 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"specmill/generator"
+)
+
+const (
+	sessionHeader = "Mcp-Session-Id"
+	versionHeader = "Mcp-Protocol-Version"
+
+	defaultSessionIdleTimeout = 30 * time.Minute
+)
+
+// HTTPHandler returns a handler implementing the MCP Streamable HTTP
+// transport on a single endpoint.
+func (s *MCPServer) HTTPHandler() http.Handler {
+	return http.HandlerFunc(s.serveStreamableHTTP)
+}
+
+// ListenAndServe serves the HTTP transports on addr until ctx is cancelled,
+// then shuts down gracefully within the server's shutdown timeout.
+func (s *MCPServer) ListenAndServe(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", s.HTTPHandler())
+	mux.Handle("/sse", s.SSEHandler())
+	mux.Handle("/messages", s.MessagesHandler())
+
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.logf("shutting down, waiting up to %s for in-flight requests", s.shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	// Stop listening first; connections become idle once the requests are
+	// drained and the event streams are closed.
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		httpServer.Close()
+		return err
+	}
+	if err := <-shutdownErr; err != nil {
+		httpServer.Close()
+		return fmt.Errorf("%w: %v", ErrShutdownTimeout, err)
+	}
+
+	return ctx.Err()
+}
+
+func (s *MCPServer) serveStreamableHTTP(w http.ResponseWriter, r *http.Request) {
+	if !validOrigin(r) {
+		http.Error(w, "Forbidden origin", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		s.handleHTTPPost(w, r)
+	case http.MethodGet:
+		s.handleHTTPStream(w, r)
+	case http.MethodDelete:
+		s.handleHTTPDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *MCPServer) handleHTTPPost(w http.ResponseWriter, r *http.Request) {
+	accept := r.Header.Get("Accept")
+	if !accepts(accept, "application/json") && !accepts(accept, "text/event-stream") {
+		http.Error(w, "Not acceptable", http.StatusNotAcceptable)
+		return
+	}
+
+	if !s.requests.begin() {
+		writeHTTPJSON(w, http.StatusServiceUnavailable, errorResponse(nil, codeInternalError, "Server is shutting down"))
+		return
+	}
+	defer s.requests.done()
+
+	data, err := s.readHTTPMessage(w, r)
+	if err != nil {
+		return
+	}
+
+	if isBatch(data) {
+		s.handleHTTPBatch(w, r, data)
+		return
+	}
+
+	var request generator.MCPRequest
+	if errResp := decodeRequest(data, &request); errResp != nil {
+		writeHTTPJSON(w, http.StatusBadRequest, errResp)
+		return
+	}
+
+	var sess *session
+	if request.Method == "initialize" && request.ID != nil {
+		sess = newSession()
+	} else {
+		var status int
+		sess, status = s.lookupSession(r)
+		if sess == nil {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		sess.acquire()
+		defer sess.release()
+	}
+
+	ctx := r.Context()
+	reply := &postReply{w: w, accept: accept, sess: sess}
+	if request.ID != nil {
+		if err := s.acquireSlot(ctx); err != nil {
+			return
+		}
+		defer s.releaseSlot()
+		ctx = withNotifier(ctx, reply.notify)
+	}
+
+	response := s.handleMessage(ctx, sess, &request)
+	if response != nil && request.Method == "initialize" && response.Error == nil {
+		s.addSession(sess)
+		s.expireWhenIdle(sess)
+		w.Header().Set(sessionHeader, sess.id)
+	}
+
+	reply.finish(response)
+}
+
+// postReply answers a POST carrying a request. Notifications sent while the
+// request is handled, such as its progress, turn the reply into an SSE
+// stream when the client accepts one, so that they arrive ahead of the
+// response; otherwise they are queued on the session's GET stream.
+type postReply struct {
+	mu       sync.Mutex
+	w        http.ResponseWriter
+	accept   string
+	sess     *session
+	streamed bool
+	finished bool
+}
+
+func (p *postReply) notify(method string, params any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.finished || !accepts(p.accept, "text/event-stream") {
+		p.sess.notify(method, params)
+		return
+	}
+
+	data, err := notification(method, params)
+	if err != nil {
+		return
+	}
+	if !p.streamed {
+		p.streamed = true
+		p.w.Header().Set("Content-Type", "text/event-stream")
+		p.w.Header().Set("Cache-Control", "no-cache")
+		p.w.WriteHeader(http.StatusOK)
+	}
+	_ = writeSSE(p.w, "message", data)
+}
+
+// finish writes the response, or 202 Accepted when there is none, ending
+// the stream if one was started.
+func (p *postReply) finish(response *generator.MCPResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finished = true
+
+	switch {
+	case p.streamed:
+		if response != nil {
+			payload, _ := json.Marshal(response)
+			_ = writeSSE(p.w, "message", payload)
+		}
+	case response == nil:
+		p.w.WriteHeader(http.StatusAccepted)
+	default:
+		writeHTTPReply(p.w, p.accept, response)
+	}
+}
+
+func (s *MCPServer) handleHTTPBatch(w http.ResponseWriter, r *http.Request, data []byte) {
+	sess, status := s.lookupSession(r)
+	if sess == nil {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	sess.acquire()
+	defer sess.release()
+
+	responses, errResp := s.handleBatch(r.Context(), sess, data)
+	switch {
+	case errResp != nil:
+		writeHTTPJSON(w, http.StatusBadRequest, errResp)
+	case len(responses) == 0:
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		writeHTTPReply(w, r.Header.Get("Accept"), responses)
+	}
+}
+
+// writeHTTPReply answers a POST with JSON when the client accepts it and
+// with a single-event SSE stream otherwise.
+func writeHTTPReply(w http.ResponseWriter, accept string, reply any) {
+	if accepts(accept, "application/json") {
+		writeHTTPJSON(w, http.StatusOK, reply)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	payload, _ := json.Marshal(reply)
+	writeSSE(w, "message", payload)
+}
+
+func (s *MCPServer) handleHTTPStream(w http.ResponseWriter, r *http.Request) {
+	if !accepts(r.Header.Get("Accept"), "text/event-stream") {
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sess, status := s.lookupSession(r)
+	if sess == nil {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	sess.mu.Lock()
+	if sess.streaming {
+		sess.mu.Unlock()
+		http.Error(w, "Stream already open for session", http.StatusConflict)
+		return
+	}
+	sess.streaming = true
+	sess.mu.Unlock()
+
+	sess.acquire()
+	defer sess.release()
+
+	defer func() {
+		sess.mu.Lock()
+		sess.streaming = false
+		sess.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	s.streamEvents(w, r, sess)
+}
+
+// readHTTPMessage reads a request body of at most maxMessageSize bytes,
+// replying with an Invalid Request error when it is larger.
+func (s *MCPServer) readHTTPMessage(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(s.maxMessageSize)))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			message := fmt.Sprintf("Invalid Request: message exceeds %d bytes", s.maxMessageSize)
+			writeHTTPJSON(w, http.StatusRequestEntityTooLarge, errorResponse(nil, codeInvalidRequest, message))
+		} else {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// streamEvents writes queued session messages as SSE "message" events until
+// the session ends, the client disconnects, or the server shuts down. On
+// shutdown, messages already queued are written before the stream closes.
+func (s *MCPServer) streamEvents(w http.ResponseWriter, r *http.Request, sess *session) {
+	for {
+		select {
+		case data := <-sess.events:
+			if err := writeSSE(w, "message", data); err != nil {
+				return
+			}
+		case <-sess.done:
+			return
+		case <-r.Context().Done():
+			return
+		case <-s.closing:
+			for {
+				select {
+				case data := <-sess.events:
+					if err := writeSSE(w, "message", data); err != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *MCPServer) handleHTTPDelete(w http.ResponseWriter, r *http.Request) {
+	sess, status := s.lookupSession(r)
+	if sess == nil {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	s.removeSession(sess)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *MCPServer) addSession(sess *session) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]*session)
+	}
+	s.sessions[sess.id] = sess
+}
+
+func (s *MCPServer) removeSession(sess *session) {
+	s.sessionsMu.Lock()
+	delete(s.sessions, sess.id)
+	s.sessionsMu.Unlock()
+
+	sess.close()
+}
+
+// expireWhenIdle removes a Streamable HTTP session once it has been idle for
+// the configured timeout, stopping its subscriptions.
+func (s *MCPServer) expireWhenIdle(sess *session) {
+	timeout := s.options.sessionIdle
+	sess.expireWhenIdle(timeout, func() {
+		s.logf("session %s expired after %s idle", sess.id, timeout)
+		s.removeSession(sess)
+	})
+}
+
+func (s *MCPServer) findSession(id string) *session {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	return s.sessions[id]
+}
+
+// lookupSession resolves the session named by the request's Mcp-Session-Id
+// header, returning the HTTP status to reply with when it cannot. Requests
+// announcing an unsupported Mcp-Protocol-Version are rejected.
+func (s *MCPServer) lookupSession(r *http.Request) (*session, int) {
+	id := r.Header.Get(sessionHeader)
+	if id == "" {
+		return nil, http.StatusBadRequest
+	}
+
+	if version := r.Header.Get(versionHeader); version != "" && !isSupportedProtocolVersion(version) {
+		return nil, http.StatusBadRequest
+	}
+
+	sess := s.findSession(id)
+	if sess == nil {
+		return nil, http.StatusNotFound
+	}
+	return sess, http.StatusOK
+}
+
+// validOrigin rejects browser requests from other origins to protect local
+// servers against DNS rebinding.
+func validOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Host == r.Host
+}
+
+func accepts(accept, mediaType string) bool {
+	if accept == "" {
+		return true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		part = strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if part == mediaType || part == "*/*" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeHTTPJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeSSE(w io.Writer, event string, data []byte) error {
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
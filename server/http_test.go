@@ -0,0 +1,300 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"specmill/generator"
+)
+
+func postMCP(t *testing.T, url, sessionID, accept, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	if sessionID != "" {
+		req.Header.Set(sessionHeader, sessionID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	return resp
+}
+
+func TestStreamableHTTP(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.HTTPHandler())
+	defer ts.Close()
+
+	const accept = "application/json, text/event-stream"
+
+	resp := postMCP(t, ts.URL, "", accept, `{"jsonrpc":"2.0","method":"initialize","params":{"protocolVersion":"2025-06-18"},"id":1}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 for initialize, got: %d", resp.StatusCode)
+	}
+	sessionID := resp.Header.Get(sessionHeader)
+	if sessionID == "" {
+		t.Fatal("Expected Mcp-Session-Id header on initialize response")
+	}
+
+	resp = postMCP(t, ts.URL, sessionID, accept, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 for notification, got: %d", resp.StatusCode)
+	}
+
+	resp = postMCP(t, ts.URL, "", accept, `{"jsonrpc":"2.0","method":"tools/list","id":2}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 without session, got: %d", resp.StatusCode)
+	}
+
+	resp = postMCP(t, ts.URL, "unknown", accept, `{"jsonrpc":"2.0","method":"tools/list","id":2}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown session, got: %d", resp.StatusCode)
+	}
+
+	resp = postMCP(t, ts.URL, sessionID, accept, `{"jsonrpc":"2.0","method":"tools/list","id":3}`)
+	var response generator.MCPResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON response, got: %s", resp.Header.Get("Content-Type"))
+	}
+	if response.Error != nil || len(response.Result) == 0 {
+		t.Errorf("Expected tools/list result, got: %+v", response)
+	}
+
+	resp = postMCP(t, ts.URL, sessionID, "text/event-stream", `{"jsonrpc":"2.0","method":"tools/list","id":4}`)
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Expected SSE response, got: %s", resp.Header.Get("Content-Type"))
+	}
+	data := readSSEData(t, bufio.NewReader(resp.Body))
+	resp.Body.Close()
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		t.Fatalf("Failed to decode SSE response: %v", err)
+	}
+	if response.ID != float64(4) {
+		t.Errorf("Expected response id 4, got: %v", response.ID)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL, nil)
+	req.Header.Set(sessionHeader, sessionID)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected 204 for delete, got: %d", resp.StatusCode)
+	}
+
+	resp = postMCP(t, ts.URL, sessionID, accept, `{"jsonrpc":"2.0","method":"tools/list","id":5}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 after session deleted, got: %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableHTTPServerStream(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.HTTPHandler())
+	defer ts.Close()
+
+	resp := postMCP(t, ts.URL, "", "application/json", `{"jsonrpc":"2.0","method":"initialize","params":{},"id":1}`)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(sessionHeader)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(sessionHeader, sessionID)
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer stream.Body.Close()
+	if stream.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 for stream, got: %d", stream.StatusCode)
+	}
+
+	second, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open second stream: %v", err)
+	}
+	second.Body.Close()
+	if second.StatusCode != http.StatusConflict {
+		t.Errorf("Expected 409 for second stream, got: %d", second.StatusCode)
+	}
+
+	sess, _ := srv.lookupSession(req)
+	sess.notify("notifications/tools/list_changed", nil)
+
+	done := make(chan string, 1)
+	go func() {
+		done <- readSSEData(t, bufio.NewReader(stream.Body))
+	}()
+
+	select {
+	case data := <-done:
+		if !strings.Contains(data, "notifications/tools/list_changed") {
+			t.Errorf("Expected notification on stream, got: %s", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for notification")
+	}
+}
+
+func TestStreamableHTTPPostStream(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "16")
+		w.Write([]byte(`[{"name":"Rex"}]`))
+	}))
+	defer upstream.Close()
+
+	srv, err := NewMCPServerFromBytes(testSpecData(upstream.URL))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.HTTPHandler())
+	defer ts.Close()
+
+	const accept = "application/json, text/event-stream"
+	resp := postMCP(t, ts.URL, "", accept, `{"jsonrpc":"2.0","method":"initialize","params":{"protocolVersion":"2025-06-18"},"id":1}`)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(sessionHeader)
+	postMCP(t, ts.URL, sessionID, accept, `{"jsonrpc":"2.0","method":"notifications/initialized"}`).Body.Close()
+
+	resp = postMCP(t, ts.URL, sessionID, accept, `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"listPets","arguments":{},"_meta":{"progressToken":"p1"}},"id":2}`)
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected an SSE reply for a call with progress, got: %s", resp.Header.Get("Content-Type"))
+	}
+
+	r := bufio.NewReader(resp.Body)
+	if data := readSSEData(t, r); !strings.Contains(data, `"method":"notifications/progress"`) || !strings.Contains(data, `"progressToken":"p1"`) {
+		t.Errorf("Expected progress before the response, got: %s", data)
+	}
+	var response generator.MCPResponse
+	for data := readSSEData(t, r); data != ""; data = readSSEData(t, r) {
+		json.Unmarshal([]byte(data), &response)
+	}
+	if response.ID != float64(2) || response.Error != nil {
+		t.Errorf("Expected the call's response to end the stream, got: %+v", response)
+	}
+
+	resp = postMCP(t, ts.URL, sessionID, accept, `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"listPets","arguments":{}},"id":3}`)
+	resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a JSON reply without notifications, got: %s", resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestStreamableHTTPSessionExpiry(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml", WithSessionIdleTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.HTTPHandler())
+	defer ts.Close()
+
+	initialize := func() string {
+		resp := postMCP(t, ts.URL, "", "application/json", `{"jsonrpc":"2.0","method":"initialize","params":{},"id":1}`)
+		resp.Body.Close()
+		return resp.Header.Get(sessionHeader)
+	}
+	ping := func(sessionID string) int {
+		resp := postMCP(t, ts.URL, sessionID, "application/json", `{"jsonrpc":"2.0","method":"ping","id":2}`)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	idle := initialize()
+	if status := ping(idle); status != http.StatusOK {
+		t.Fatalf("Expected 200 for a live session, got: %d", status)
+	}
+
+	streaming := initialize()
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(sessionHeader, streaming)
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer stream.Body.Close()
+
+	time.Sleep(200 * time.Millisecond)
+
+	if status := ping(idle); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an expired session, got: %d", status)
+	}
+	if status := ping(streaming); status != http.StatusOK {
+		t.Errorf("Expected a session with an open stream to be kept, got: %d", status)
+	}
+}
+
+func TestStreamableHTTPOrigin(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.HTTPHandler())
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`{"jsonrpc":"2.0","method":"initialize","id":1}`))
+	req.Header.Set("Origin", "http://evil.example.com")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 for foreign origin, got: %d", resp.StatusCode)
+	}
+}
+
+func readSSEData(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+
+	var data string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return data
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" && data != "" {
+			return data
+		}
+		if strings.HasPrefix(line, "data: ") {
+			data += strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
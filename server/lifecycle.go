@@ -0,0 +1,146 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"specmill/generator"
+)
+
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+
+	codeResourceNotFound = -32002
+)
+
+type sessionState int
+
+const (
+	// stateNew sessions only accept initialize and ping.
+	stateNew sessionState = iota
+	// stateInitializing sessions have been answered an initialize request and
+	// are waiting for the client's notifications/initialized.
+	stateInitializing
+	stateReady
+)
+
+// handleMessage applies JSON-RPC and MCP lifecycle rules to an incoming
+// message before dispatching it. It returns nil for messages that must not
+// be answered, such as notifications and responses from the client.
+//
+// Requests run under a context that is cancelled when the client sends
+// notifications/cancelled for them or the session ends; cancelled requests
+// are not answered.
+func (s *MCPServer) handleMessage(ctx context.Context, sess *session, request *generator.MCPRequest) *generator.MCPResponse {
+	return s.dispatchMessage(ctx, nil, sess, request)
+}
+
+// handleQueuedMessage is handleMessage for a request that has yet to take a
+// worker slot. The request is tracked while it waits, so that the client can
+// cancel it, and is answered with an error if queue is done before a slot
+// frees up.
+func (s *MCPServer) handleQueuedMessage(ctx, queue context.Context, sess *session, request *generator.MCPRequest) *generator.MCPResponse {
+	return s.dispatchMessage(ctx, queue, sess, request)
+}
+
+// dispatchMessage implements handleMessage and handleQueuedMessage; queue is
+// nil when the caller already holds a slot.
+func (s *MCPServer) dispatchMessage(ctx, queue context.Context, sess *session, request *generator.MCPRequest) *generator.MCPResponse {
+	if request.ID == nil {
+		if request.Jsonrpc == "2.0" {
+			s.handleNotification(sess, request)
+		}
+		return nil
+	}
+
+	if request.Method == "" {
+		if request.Result != nil || request.Error != nil {
+			return nil
+		}
+		return errorResponse(request.ID, codeInvalidRequest, "Invalid Request: method is required")
+	}
+
+	if request.Jsonrpc != "2.0" {
+		return errorResponse(request.ID, codeInvalidRequest, "Invalid Request: jsonrpc must be \"2.0\"")
+	}
+
+	state := sess.getState()
+	switch {
+	case request.Method == "ping":
+	case request.Method == "initialize":
+		if state != stateNew {
+			return errorResponse(request.ID, codeInvalidRequest, "Invalid Request: session already initialized")
+		}
+	case state == stateNew:
+		return errorResponse(request.ID, codeInvalidRequest, "Invalid Request: session not initialized")
+	}
+
+	ctx, cancel := context.WithCancel(withSession(withProtocolVersion(ctx, sess.getProtocolVersion()), sess))
+	defer cancel()
+
+	call := sess.track(request.ID, cancel)
+	if queue != nil {
+		if err := s.waitForSlot(ctx, queue); err != nil {
+			if sess.untrack(request.ID, call) {
+				return nil
+			}
+			return errorResponse(request.ID, codeInternalError, "Request not handled: "+err.Error())
+		}
+		defer s.releaseSlot()
+	}
+	response := s.handleRequest(ctx, request)
+	if sess.untrack(request.ID, call) {
+		return nil
+	}
+
+	if request.Method == "initialize" && response.Error == nil {
+		version, _ := negotiateProtocolVersion(request.Params)
+		sess.setProtocolVersion(version)
+		sess.setState(stateInitializing)
+	}
+	return response
+}
+
+// decodeRequest parses a single JSON-RPC message into request. It returns a
+// Parse error for malformed JSON and an Invalid Request error for JSON that
+// is not a message object.
+func decodeRequest(data []byte, request *generator.MCPRequest) *generator.MCPResponse {
+	if !json.Valid(data) {
+		return errorResponse(nil, codeParseError, "Parse error")
+	}
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '{' || json.Unmarshal(data, request) != nil {
+		return errorResponse(nil, codeInvalidRequest, "Invalid Request")
+	}
+	return nil
+}
+
+func (s *MCPServer) handleNotification(sess *session, request *generator.MCPRequest) {
+	switch request.Method {
+	case "notifications/initialized":
+		if sess.getState() == stateInitializing {
+			sess.setState(stateReady)
+		}
+	case "notifications/cancelled":
+		var params struct {
+			RequestID interface{} `json:"requestId"`
+			Reason    string      `json:"reason"`
+		}
+		if err := json.Unmarshal(request.Params, &params); err == nil && params.RequestID != nil {
+			sess.cancel(params.RequestID)
+		}
+	}
+}
+
+func (s *MCPServer) handlePing(request *generator.MCPRequest) *generator.MCPResponse {
+	return &generator.MCPResponse{
+		Jsonrpc: "2.0",
+		Result:  json.RawMessage(`{}`),
+		ID:      request.ID,
+	}
+}
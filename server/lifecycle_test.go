@@ -0,0 +1,446 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"specmill/generator"
+)
+
+func TestLifecycle(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	sess := newSession()
+
+	steps := []struct {
+		name      string
+		request   string
+		errorCode int
+		noReply   bool
+		state     sessionState
+	}{
+		{
+			name:      "Request before initialize",
+			request:   `{"jsonrpc":"2.0","method":"tools/list","id":1}`,
+			errorCode: codeInvalidRequest,
+			state:     stateNew,
+		},
+		{
+			name:    "Ping before initialize",
+			request: `{"jsonrpc":"2.0","method":"ping","id":2}`,
+			state:   stateNew,
+		},
+		{
+			name:      "Invalid jsonrpc version",
+			request:   `{"jsonrpc":"1.0","method":"initialize","id":3}`,
+			errorCode: codeInvalidRequest,
+			state:     stateNew,
+		},
+		{
+			name:    "Initialize",
+			request: `{"jsonrpc":"2.0","method":"initialize","params":{"protocolVersion":"2025-06-18"},"id":4}`,
+			state:   stateInitializing,
+		},
+		{
+			name:      "Second initialize",
+			request:   `{"jsonrpc":"2.0","method":"initialize","params":{"protocolVersion":"2025-06-18"},"id":5}`,
+			errorCode: codeInvalidRequest,
+			state:     stateInitializing,
+		},
+		{
+			name:    "Initialized notification",
+			request: `{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+			noReply: true,
+			state:   stateReady,
+		},
+		{
+			name:    "Unknown notification",
+			request: `{"jsonrpc":"2.0","method":"notifications/unknown"}`,
+			noReply: true,
+			state:   stateReady,
+		},
+		{
+			name:    "Client response",
+			request: `{"jsonrpc":"2.0","result":{},"id":"server-1"}`,
+			noReply: true,
+			state:   stateReady,
+		},
+		{
+			name:    "Client error response",
+			request: `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":"server-2"}`,
+			noReply: true,
+			state:   stateReady,
+		},
+		{
+			name:      "Request without method",
+			request:   `{"jsonrpc":"2.0","id":7}`,
+			errorCode: codeInvalidRequest,
+			state:     stateReady,
+		},
+		{
+			name:    "Request after initialize",
+			request: `{"jsonrpc":"2.0","method":"tools/list","id":6}`,
+			state:   stateReady,
+		},
+	}
+
+	for _, step := range steps {
+		var request generator.MCPRequest
+		if err := json.Unmarshal([]byte(step.request), &request); err != nil {
+			t.Fatalf("%s: failed to parse request: %v", step.name, err)
+		}
+
+		response := srv.handleMessage(context.Background(), sess, &request)
+
+		switch {
+		case step.noReply:
+			if response != nil {
+				t.Errorf("%s: expected no reply, got: %+v", step.name, response)
+			}
+		case response == nil:
+			t.Errorf("%s: expected a reply", step.name)
+		case step.errorCode != 0:
+			if response.Error == nil || response.Error.Code != step.errorCode {
+				t.Errorf("%s: expected error %d, got: %+v", step.name, step.errorCode, response.Error)
+			}
+		case response.Error != nil:
+			t.Errorf("%s: expected no error, got: %+v", step.name, response.Error)
+		}
+
+		if state := sess.getState(); state != step.state {
+			t.Errorf("%s: expected state %d, got: %d", step.name, step.state, state)
+		}
+	}
+}
+
+func TestDecodeRequest(t *testing.T) {
+	tests := []struct {
+		data string
+		code int
+	}{
+		{data: `{"jsonrpc":"2.0","method":"ping","id":1}`},
+		{data: `{"jsonrpc":"2.0","method":`, code: codeParseError},
+		{data: `123`, code: codeInvalidRequest},
+		{data: `null`, code: codeInvalidRequest},
+		{data: `"ping"`, code: codeInvalidRequest},
+		{data: `{"jsonrpc":"2.0","method":5,"id":1}`, code: codeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		var request generator.MCPRequest
+		errResp := decodeRequest([]byte(tt.data), &request)
+		switch {
+		case tt.code == 0 && errResp != nil:
+			t.Errorf("%s: expected no error, got: %+v", tt.data, errResp.Error)
+		case tt.code != 0 && (errResp == nil || errResp.Error.Code != tt.code):
+			t.Errorf("%s: expected error %d, got: %+v", tt.data, tt.code, errResp)
+		}
+	}
+}
+
+func writeTestSpec(t *testing.T, serverURL string) string {
+	t.Helper()
+
+	spec := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: ` + serverURL + `
+paths:
+  /slow:
+    get:
+      operationId: slowCall
+      responses:
+        "200":
+          description: OK
+`
+	specPath := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(specPath, []byte(spec), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	return specPath
+}
+
+func TestCancelledRequest(t *testing.T) {
+	started := make(chan struct{})
+	aborted := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+		close(aborted)
+	}))
+	defer upstream.Close()
+
+	srv, err := NewMCPServer(writeTestSpec(t, upstream.URL))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	sess := newSession()
+	sess.setState(stateReady)
+
+	request := generator.MCPRequest{
+		Jsonrpc: "2.0",
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"slowCall","arguments":{}}`),
+		ID:      "call-1",
+	}
+
+	responses := make(chan *generator.MCPResponse, 1)
+	go func() {
+		responses <- srv.handleMessage(context.Background(), sess, &request)
+	}()
+
+	<-started
+
+	cancel := generator.MCPRequest{
+		Jsonrpc: "2.0",
+		Method:  "notifications/cancelled",
+		Params:  json.RawMessage(`{"requestId":"call-1","reason":"user aborted"}`),
+	}
+	if response := srv.handleMessage(context.Background(), sess, &cancel); response != nil {
+		t.Errorf("Expected no reply to cancellation, got: %+v", response)
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Upstream request was not aborted")
+	}
+
+	select {
+	case response := <-responses:
+		if response != nil {
+			t.Errorf("Expected cancelled request to go unanswered, got: %+v", response)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Cancelled request did not return")
+	}
+}
+
+func TestCancelWhilePoolFull(t *testing.T) {
+	started := make(chan struct{})
+	aborted := make(chan struct{})
+	release := make(chan struct{})
+	var count int
+	var mu sync.Mutex
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		count++
+		first := count == 1
+		mu.Unlock()
+		if first {
+			close(started)
+			select {
+			case <-r.Context().Done():
+				close(aborted)
+			case <-release:
+			}
+		}
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	srv, err := NewMCPServer(writeTestSpec(t, upstream.URL), WithMaxConcurrency(1))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(context.Background(), inR, outW)
+		outW.Close()
+	}()
+
+	ids := make(chan float64, 10)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			var response generator.MCPResponse
+			if err := json.Unmarshal(scanner.Bytes(), &response); err == nil {
+				id, _ := response.ID.(float64)
+				ids <- id
+			}
+		}
+		close(ids)
+	}()
+	expect := func(id float64) {
+		t.Helper()
+		select {
+		case got := <-ids:
+			if got != id {
+				t.Fatalf("Expected response %v, got: %v", id, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for response %v", id)
+		}
+	}
+	// Messages are written in order without blocking the test on a reader
+	// that stopped reading.
+	input := make(chan string, 10)
+	go func() {
+		for line := range input {
+			io.WriteString(inW, line+"\n")
+		}
+		inW.Close()
+	}()
+	send := func(line string) { input <- line }
+
+	send(`{"jsonrpc":"2.0","method":"initialize","params":{"protocolVersion":"2025-06-18"},"id":0}`)
+	expect(0)
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slowCall","arguments":{}},"id":1}`)
+	<-started
+	send(`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slowCall","arguments":{}},"id":2}`)
+
+	send(`{"jsonrpc":"2.0","method":"ping","id":3}`)
+	expect(3)
+
+	send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`)
+	select {
+	case <-aborted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Upstream request was not aborted")
+	}
+	expect(2)
+
+	close(input)
+	if err := <-done; err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	for id := range ids {
+		t.Errorf("Expected cancelled request to go unanswered, got response %v", id)
+	}
+}
+
+func TestQueuedRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { close(started) })
+		<-release
+	}))
+	defer upstream.Close()
+
+	srv, err := NewMCPServer(writeTestSpec(t, upstream.URL), WithMaxConcurrency(1), WithMaxQueuedRequests(2), WithShutdownTimeout(5*time.Second))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(ctx, inR, outW)
+		outW.Close()
+	}()
+
+	responses := make(chan generator.MCPResponse, 10)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			var response generator.MCPResponse
+			if err := json.Unmarshal(scanner.Bytes(), &response); err == nil {
+				responses <- response
+			}
+		}
+		close(responses)
+	}()
+	expect := func(id float64, isError bool) {
+		t.Helper()
+		select {
+		case response := <-responses:
+			if response.ID != id || (response.Error != nil) != isError {
+				t.Fatalf("Expected response %v with error=%v, got: %+v", id, isError, response)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for response %v", id)
+		}
+	}
+	input := make(chan string, 10)
+	go func() {
+		for line := range input {
+			io.WriteString(inW, line+"\n")
+		}
+	}()
+	defer close(input)
+	send := func(line string) { input <- line }
+
+	send(`{"jsonrpc":"2.0","method":"initialize","params":{"protocolVersion":"2025-06-18"},"id":0}`)
+	expect(0, false)
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slowCall","arguments":{}},"id":1}`)
+	<-started
+
+	// Requests 2 and 3 wait for the busy worker and request 4 finds the
+	// queue full.
+	send(`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slowCall","arguments":{}},"id":2}`)
+	send(`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slowCall","arguments":{}},"id":3}`)
+	send(`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slowCall","arguments":{}},"id":4}`)
+	expect(4, true)
+
+	// A queued request can be cancelled before it starts.
+	send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":3}}`)
+	send(`{"jsonrpc":"2.0","method":"ping","id":5}`)
+	expect(5, false)
+
+	// On shutdown the queued request is answered with an error and the
+	// running one is drained.
+	cancel()
+	expect(2, true)
+	close(release)
+	expect(1, false)
+
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+	for response := range responses {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	var buf bytes.Buffer
+	out := &messageWriter{w: bufio.NewWriter(&buf)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			out.write(&generator.MCPResponse{Jsonrpc: "2.0", Result: json.RawMessage(`{}`), ID: id})
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 50 {
+		t.Fatalf("Expected 50 messages, got: %d", len(lines))
+	}
+	for _, line := range lines {
+		var response generator.MCPResponse
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Errorf("Interleaved output: %q", line)
+		}
+	}
+}
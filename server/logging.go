@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"specmill/generator"
+)
+
+// defaultLogLevel is the minimum level of notifications/message sent to
+// sessions that have not called logging/setLevel.
+const defaultLogLevel = "info"
+
+// logLevels ranks the syslog severities used by MCP logging.
+var logLevels = map[string]int{
+	"debug":     0,
+	"info":      1,
+	"notice":    2,
+	"warning":   3,
+	"error":     4,
+	"critical":  5,
+	"alert":     6,
+	"emergency": 7,
+}
+
+func (s *MCPServer) handleSetLevel(ctx context.Context, request *generator.MCPRequest) *generator.MCPResponse {
+	var params generator.SetLevelParams
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params")
+	}
+	if _, ok := logLevels[params.Level]; !ok {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params: unknown log level "+params.Level)
+	}
+
+	if sess := sessionFromContext(ctx); sess != nil {
+		sess.setLogLevel(params.Level)
+	}
+
+	return &generator.MCPResponse{
+		Jsonrpc: "2.0",
+		Result:  json.RawMessage(`{}`),
+		ID:      request.ID,
+	}
+}
+
+// logNotifier returns a function sending notifications/message alongside
+// the request for messages at or above the session's log level.
+func logNotifier(ctx context.Context) generator.LogFunc {
+	sess := sessionFromContext(ctx)
+	notify := requestNotifier(ctx)
+
+	return func(level, logger string, data any) {
+		if sess == nil || logLevels[level] < logLevels[sess.getLogLevel()] {
+			return
+		}
+		notify("notifications/message", map[string]any{
+			"level":  level,
+			"logger": logger,
+			"data":   data,
+		})
+	}
+}
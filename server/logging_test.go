@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"specmill/generator"
+)
+
+func TestSetLevel(t *testing.T) {
+	srv, err := NewMCPServerFromBytes(testSpecData("http://localhost"))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	sess := newSession()
+	ctx := withSession(context.Background(), sess)
+	log := logNotifier(ctx)
+
+	log("debug", "listPets", "hidden")
+	log("info", "listPets", map[string]any{"n": 1})
+	events := drainEvents(sess)
+	if len(events) != 1 || !strings.Contains(events[0], `"method":"notifications/message"`) || !strings.Contains(events[0], `"logger":"listPets"`) {
+		t.Fatalf("Expected one info message at the default level, got: %v", events)
+	}
+
+	tests := []struct {
+		name    string
+		params  string
+		code    int
+		visible bool
+	}{
+		{name: "Raise level", params: `{"level":"error"}`, visible: false},
+		{name: "Lower level", params: `{"level":"debug"}`, visible: true},
+		{name: "Unknown level", params: `{"level":"verbose"}`, code: codeInvalidParams, visible: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := srv.handleSetLevel(ctx, &generator.MCPRequest{Jsonrpc: "2.0", Method: "logging/setLevel", Params: json.RawMessage(tt.params), ID: 1})
+			if tt.code != 0 {
+				if response.Error == nil || response.Error.Code != tt.code {
+					t.Errorf("Expected error %d, got: %+v", tt.code, response)
+				}
+			} else if response.Error != nil {
+				t.Errorf("Unexpected error: %+v", response.Error)
+			}
+
+			log("info", "listPets", "event")
+			if events := drainEvents(sess); (len(events) == 1) != tt.visible {
+				t.Errorf("Expected info message visible %v, got: %v", tt.visible, events)
+			}
+		})
+	}
+}
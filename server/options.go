@@ -0,0 +1,172 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"specmill/generator"
+	"specmill/parser"
+)
+
+type options struct {
+	config          *generator.Config
+	client          *http.Client
+	requestEditor   generator.RequestEditor
+	filter          generator.OperationFilter
+	logger          *log.Logger
+	maxConcurrency  int
+	maxQueued       int
+	maxMessageSize  int
+	pageSize        int
+	shutdownTimeout time.Duration
+	sessionIdle     time.Duration
+}
+
+// Option configures an MCPServer created by one of the NewMCPServer
+// constructors.
+type Option func(*options)
+
+func defaultOptions() *options {
+	return &options{
+		config:          &generator.Config{},
+		logger:          log.New(io.Discard, "", 0),
+		maxConcurrency:  defaultMaxConcurrency,
+		maxQueued:       defaultMaxQueued,
+		maxMessageSize:  defaultMaxMessageSize,
+		shutdownTimeout: defaultShutdownTimeout,
+		sessionIdle:     defaultSessionIdleTimeout,
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *options) httpClient() *http.Client {
+	if o.client != nil {
+		return o.client
+	}
+	return http.DefaultClient
+}
+
+// newGenerator builds a generator for spec with the configured hooks.
+func (o *options) newGenerator(spec *parser.OpenAPISpec, raw []byte) (*generator.MCPGenerator, error) {
+	gen := generator.NewMCPGeneratorWithConfig(spec, o.config)
+	gen.SetRawSpec(raw)
+	gen.SetHTTPClient(o.client)
+	gen.SetRequestEditor(o.requestEditor)
+	gen.SetOperationFilter(o.filter)
+	if err := gen.GenerateTools(); err != nil {
+		return nil, fmt.Errorf("failed to generate tools: %w", err)
+	}
+	return gen, nil
+}
+
+func WithConfig(cfg *generator.Config) Option {
+	return func(o *options) {
+		if cfg != nil {
+			o.config = cfg
+		}
+	}
+}
+
+// WithHTTPClient sets the client used to call the upstream API.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithAuth sets a function that adds credentials to every upstream request.
+func WithAuth(editor generator.RequestEditor) Option {
+	return func(o *options) {
+		o.requestEditor = editor
+	}
+}
+
+// WithOperationFilter exposes only the operations for which filter returns
+// true.
+func WithOperationFilter(filter generator.OperationFilter) Option {
+	return func(o *options) {
+		o.filter = filter
+	}
+}
+
+// WithLogger sets the logger for server diagnostics. Logging is disabled by
+// default since stdout carries the protocol.
+func WithLogger(logger *log.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithMaxConcurrency limits the number of requests handled at once.
+func WithMaxConcurrency(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxConcurrency = n
+		}
+	}
+}
+
+// WithMaxQueuedRequests limits the number of stdio requests that may wait for
+// a worker once WithMaxConcurrency requests are running. Further requests
+// are rejected until the queue drains.
+func WithMaxQueuedRequests(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxQueued = n
+		}
+	}
+}
+
+// WithMaxMessageSize limits the size in bytes of a single incoming JSON-RPC
+// message. Larger messages are rejected with an Invalid Request error.
+func WithMaxMessageSize(size int) Option {
+	return func(o *options) {
+		if size > 0 {
+			o.maxMessageSize = size
+		}
+	}
+}
+
+// WithPageSize splits tools, resources, resource templates and prompts
+// listings into pages of at most size entries. Listings are not paginated by
+// default.
+func WithPageSize(size int) Option {
+	return func(o *options) {
+		if size >= 0 {
+			o.pageSize = size
+		}
+	}
+}
+
+// WithShutdownTimeout sets how long in-flight requests may run after
+// shutdown begins before they are cancelled.
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.shutdownTimeout = d
+		}
+	}
+}
+
+// WithSessionIdleTimeout sets how long a Streamable HTTP session may go
+// without requests or an open stream before it is ended, so that sessions of
+// clients that disappear without sending DELETE are not kept forever.
+func WithSessionIdleTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.sessionIdle = d
+		}
+	}
+}
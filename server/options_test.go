@@ -0,0 +1,127 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"specmill/generator"
+	"specmill/parser"
+)
+
+func testSpecData(serverURL string) []byte {
+	return []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Embedded API", "version": "1.0.0"},
+		"servers": [{"url": "` + serverURL + `"}],
+		"paths": {
+			"/pets": {
+				"get": {"operationId": "listPets", "responses": {"200": {"description": "OK"}}},
+				"post": {"operationId": "createPet", "responses": {"201": {"description": "Created"}}}
+			}
+		}
+	}`)
+}
+
+func TestServeWithOptions(t *testing.T) {
+	var authorization string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer upstream.Close()
+
+	readOnly := func(method, path string, op *parser.Operation) bool {
+		return method == "get"
+	}
+
+	srv, err := NewMCPServerFromBytes(testSpecData(upstream.URL),
+		WithHTTPClient(upstream.Client()),
+		WithAuth(generator.BearerAuth("secret")),
+		WithOperationFilter(readOnly),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","method":"initialize","params":{"protocolVersion":"2025-06-18"},"id":1}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","method":"tools/list","id":2}`,
+		`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"listPets","arguments":{}},"id":3}`,
+	}, "\n")
+
+	var output strings.Builder
+	if err := srv.Serve(context.Background(), strings.NewReader(input), &output); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	responses := make(map[float64]generator.MCPResponse)
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var response generator.MCPResponse
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		responses[response.ID.(float64)] = response
+	}
+	if len(responses) != 3 {
+		t.Fatalf("Expected 3 responses, got: %d", len(responses))
+	}
+
+	var tools generator.ListToolsResult
+	if err := json.Unmarshal(responses[2].Result, &tools); err != nil {
+		t.Fatalf("Failed to decode tools: %v", err)
+	}
+	if len(tools.Tools) != 1 || tools.Tools[0].Name != "listPets" {
+		t.Errorf("Expected only listPets after filtering, got: %+v", tools.Tools)
+	}
+
+	if responses[3].Error != nil {
+		t.Errorf("Expected tool call to succeed, got: %+v", responses[3].Error)
+	}
+	if authorization != "Bearer secret" {
+		t.Errorf("Expected bearer token upstream, got: %s", authorization)
+	}
+}
+
+func TestServeContextCancel(t *testing.T) {
+	srv, err := NewMCPServerFromBytes(testSpecData("http://localhost"))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	inR, inW := io.Pipe()
+	defer inW.Close()
+	outR, outW := io.Pipe()
+	defer outR.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(ctx, inR, outW)
+	}()
+
+	go inW.Write([]byte(`{"jsonrpc":"2.0","method":"ping","id":1}` + "\n"))
+	line, err := bufio.NewReader(outR).ReadString('\n')
+	if err != nil || !strings.Contains(line, `"result":{}`) {
+		t.Fatalf("Expected ping result, got: %q (%v)", line, err)
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve did not return after cancellation")
+	}
+}
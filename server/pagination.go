@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"specmill/generator"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor is the decoded form of the opaque cursors handed to clients.
+// After names the last entry of the previous page, so that paging stays
+// stable when the listing changes between requests; Offset is used when that
+// entry has since been removed.
+type pageCursor struct {
+	After  string `json:"a"`
+	Offset int    `json:"o"`
+}
+
+func encodeCursor(c pageCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string) (pageCursor, error) {
+	var c pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || json.Unmarshal(data, &c) != nil || c.After == "" || c.Offset < 0 {
+		return pageCursor{}, errInvalidCursor
+	}
+	return c, nil
+}
+
+// paginate returns the page of items following cursor and the cursor of the
+// next page, which is empty on the last page. A pageSize of 0 returns every
+// item. key identifies an item within the listing.
+func paginate[T any](items []T, cursor string, pageSize int, key func(T) string) ([]T, string, error) {
+	start := 0
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start = min(c.Offset, len(items))
+		for i, item := range items {
+			if key(item) == c.After {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	if pageSize <= 0 || len(items)-start <= pageSize {
+		return items[start:], "", nil
+	}
+
+	end := start + pageSize
+	next := encodeCursor(pageCursor{After: key(items[end-1]), Offset: end})
+	return items[start:end], next, nil
+}
+
+// listCursor extracts the cursor from a list request's params.
+func listCursor(request *generator.MCPRequest) (string, error) {
+	if len(request.Params) == 0 || string(request.Params) == "null" {
+		return "", nil
+	}
+	var params generator.ListParams
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		return "", err
+	}
+	return params.Cursor, nil
+}
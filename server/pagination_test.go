@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"specmill/generator"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	key := func(item string) string { return item }
+
+	tests := []struct {
+		name     string
+		items    []string
+		cursor   string
+		pageSize int
+		expected []string
+		next     bool
+		wantErr  bool
+	}{
+		{
+			name:     "Disabled",
+			items:    items,
+			expected: items,
+		},
+		{
+			name:     "First page",
+			items:    items,
+			pageSize: 2,
+			expected: []string{"a", "b"},
+			next:     true,
+		},
+		{
+			name:     "Middle page",
+			items:    items,
+			cursor:   encodeCursor(pageCursor{After: "b", Offset: 2}),
+			pageSize: 2,
+			expected: []string{"c", "d"},
+			next:     true,
+		},
+		{
+			name:     "Last page",
+			items:    items,
+			cursor:   encodeCursor(pageCursor{After: "d", Offset: 4}),
+			pageSize: 2,
+			expected: []string{"e"},
+		},
+		{
+			name:     "Entry inserted before cursor",
+			items:    []string{"0", "a", "b", "c", "d", "e"},
+			cursor:   encodeCursor(pageCursor{After: "b", Offset: 2}),
+			pageSize: 2,
+			expected: []string{"c", "d"},
+			next:     true,
+		},
+		{
+			name:     "Cursor entry removed",
+			items:    []string{"a", "c", "d", "e"},
+			cursor:   encodeCursor(pageCursor{After: "b", Offset: 2}),
+			pageSize: 2,
+			expected: []string{"d", "e"},
+		},
+		{
+			name:     "Offset past end",
+			items:    []string{"a"},
+			cursor:   encodeCursor(pageCursor{After: "z", Offset: 10}),
+			pageSize: 2,
+			expected: []string{},
+		},
+		{
+			name:    "Malformed cursor",
+			items:   items,
+			cursor:  "not a cursor",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, next, err := paginate(tt.items, tt.cursor, tt.pageSize, key)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error for malformed cursor")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to paginate: %v", err)
+			}
+			if strings.Join(page, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected page %v, got: %v", tt.expected, page)
+			}
+			if (next != "") != tt.next {
+				t.Errorf("Expected next cursor %v, got: %q", tt.next, next)
+			}
+		})
+	}
+}
+
+func TestListToolsPagination(t *testing.T) {
+	srv, err := NewMCPServerFromBytes(testSpecData("http://localhost"), WithPageSize(1))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+	ctx := withSession(context.Background(), newSession())
+
+	var names []string
+	cursor := ""
+	for page := 0; page < 3; page++ {
+		params, _ := json.Marshal(generator.ListParams{Cursor: cursor})
+		response := srv.handleListTools(ctx, &generator.MCPRequest{Jsonrpc: "2.0", Method: "tools/list", Params: params, ID: page})
+		if response.Error != nil {
+			t.Fatalf("Unexpected error: %+v", response.Error)
+		}
+
+		var result generator.ListToolsResult
+		if err := json.Unmarshal(response.Result, &result); err != nil {
+			t.Fatalf("Failed to decode tools: %v", err)
+		}
+		if len(result.Tools) != 1 {
+			t.Fatalf("Expected 1 tool per page, got: %d", len(result.Tools))
+		}
+		names = append(names, result.Tools[0].Name)
+
+		cursor = result.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	if strings.Join(names, ",") != "listPets,createPet" {
+		t.Errorf("Expected every tool once, got: %v", names)
+	}
+
+	response := srv.handleListTools(ctx, &generator.MCPRequest{Jsonrpc: "2.0", Method: "tools/list", Params: json.RawMessage(`{"cursor":"!!"}`), ID: 9})
+	if response.Error == nil || response.Error.Code != codeInvalidParams {
+		t.Errorf("Expected invalid params for malformed cursor, got: %+v", response)
+	}
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+
+	"specmill/generator"
+)
+
+// progressNotifier returns a function sending notifications/progress for
+// token alongside the request. Messages were added to progress
+// notifications in 2025-03-26 and are omitted for older clients.
+func progressNotifier(ctx context.Context, token any) generator.ProgressFunc {
+	notify := requestNotifier(ctx)
+	withMessage := protocolVersion(ctx) >= "2025-03-26"
+
+	return func(progress, total float64, message string) {
+		if notify == nil {
+			return
+		}
+
+		params := map[string]any{
+			"progressToken": token,
+			"progress":      progress,
+		}
+		if total > 0 {
+			params["total"] = total
+		}
+		if withMessage && message != "" {
+			params["message"] = message
+		}
+		notify("notifications/progress", params)
+	}
+}
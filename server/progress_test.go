@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"specmill/generator"
+)
+
+func TestCallToolProgress(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"name":"Rex"}]`))
+	}))
+	defer upstream.Close()
+
+	srv, err := NewMCPServerFromBytes(testSpecData(upstream.URL))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		version     string
+		params      string
+		expected    int
+		withMessage bool
+	}{
+		{
+			name:     "Without token",
+			version:  "2025-06-18",
+			params:   `{"name":"listPets","arguments":{}}`,
+			expected: 0,
+		},
+		{
+			name:        "With token",
+			version:     "2025-06-18",
+			params:      `{"name":"listPets","arguments":{},"_meta":{"progressToken":"call-1"}}`,
+			expected:    1,
+			withMessage: true,
+		},
+		{
+			name:     "Legacy client",
+			version:  "2024-11-05",
+			params:   `{"name":"listPets","arguments":{},"_meta":{"progressToken":7}}`,
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := newSession()
+			ctx := withProtocolVersion(withSession(context.Background(), sess), tt.version)
+
+			response := srv.handleCallTool(ctx, &generator.MCPRequest{Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(tt.params), ID: 1})
+			if response.Error != nil {
+				t.Fatalf("Unexpected error: %+v", response.Error)
+			}
+
+			events := drainEvents(sess)
+			if len(events) != tt.expected {
+				t.Fatalf("Expected %d progress notifications, got: %v", tt.expected, events)
+			}
+			for _, event := range events {
+				var message struct {
+					Method string         `json:"method"`
+					Params map[string]any `json:"params"`
+				}
+				if err := json.Unmarshal([]byte(event), &message); err != nil {
+					t.Fatalf("Failed to decode notification: %v", err)
+				}
+				if message.Method != "notifications/progress" {
+					t.Errorf("Expected notifications/progress, got: %s", message.Method)
+				}
+				if message.Params["progressToken"] == nil || message.Params["progress"] == nil {
+					t.Errorf("Expected token and progress, got: %v", message.Params)
+				}
+				text, hasMessage := message.Params["message"].(string)
+				if hasMessage != tt.withMessage || (hasMessage && !strings.HasPrefix(text, "Downloaded ")) {
+					t.Errorf("Unexpected progress message: %q", text)
+				}
+			}
+		})
+	}
+}
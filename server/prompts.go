@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+
+	"specmill/generator"
+)
+
+func (s *MCPServer) handleListPrompts(request *generator.MCPRequest) *generator.MCPResponse {
+	cursor, err := listCursor(request)
+	if err != nil {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params")
+	}
+
+	prompts, next, err := paginate(s.generator.Load().GetPrompts(), cursor, s.pageSize, func(prompt generator.MCPPrompt) string {
+		return prompt.Name
+	})
+	if err != nil {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params: "+err.Error())
+	}
+
+	result := generator.ListPromptsResult{
+		Prompts:    prompts,
+		NextCursor: next,
+	}
+
+	resultBytes, _ := json.Marshal(result)
+	return &generator.MCPResponse{
+		Jsonrpc: "2.0",
+		Result:  json.RawMessage(resultBytes),
+		ID:      request.ID,
+	}
+}
+
+func (s *MCPServer) handleGetPrompt(request *generator.MCPRequest) *generator.MCPResponse {
+	var params generator.GetPromptParams
+	if err := json.Unmarshal(request.Params, &params); err != nil || params.Name == "" {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params")
+	}
+
+	result, err := s.generator.Load().GetPrompt(params.Name, params.Arguments)
+	if errors.Is(err, generator.ErrPromptNotFound) || errors.Is(err, generator.ErrInvalidPromptArguments) {
+		return errorResponse(request.ID, codeInvalidParams, err.Error())
+	}
+	if err != nil {
+		return errorResponse(request.ID, codeInternalError, err.Error())
+	}
+
+	resultBytes, _ := json.Marshal(result)
+	return &generator.MCPResponse{
+		Jsonrpc: "2.0",
+		Result:  json.RawMessage(resultBytes),
+		ID:      request.ID,
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"specmill/generator"
+)
+
+func TestPrompts(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	response := srv.handleRequest(context.Background(), &generator.MCPRequest{Jsonrpc: "2.0", Method: "prompts/list", ID: 1})
+	var list generator.ListPromptsResult
+	if err := json.Unmarshal(response.Result, &list); err != nil {
+		t.Fatalf("Failed to decode prompts: %v", err)
+	}
+	if len(list.Prompts) == 0 || list.Prompts[0].Name != "explore_pet" {
+		t.Errorf("Expected explore_pet prompt first, got: %+v", list.Prompts)
+	}
+
+	response = srv.handleRequest(context.Background(), &generator.MCPRequest{
+		Jsonrpc: "2.0",
+		Method:  "prompts/get",
+		Params:  json.RawMessage(`{"name":"getPetById","arguments":{"petId":"5"}}`),
+		ID:      2,
+	})
+	var prompt generator.GetPromptResult
+	if err := json.Unmarshal(response.Result, &prompt); err != nil {
+		t.Fatalf("Failed to decode prompt: %v", err)
+	}
+	if len(prompt.Messages) != 1 || !strings.Contains(prompt.Messages[0].Content.Text, "- petId: 5") {
+		t.Errorf("Expected rendered prompt, got: %+v", prompt.Messages)
+	}
+
+	response = srv.handleRequest(context.Background(), &generator.MCPRequest{
+		Jsonrpc: "2.0",
+		Method:  "prompts/get",
+		Params:  json.RawMessage(`{"name":"getPetById"}`),
+		ID:      3,
+	})
+	if response.Error == nil || response.Error.Code != codeInvalidParams {
+		t.Errorf("Expected invalid params for missing argument, got: %+v", response.Error)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	response := srv.handleRequest(context.Background(), &generator.MCPRequest{
+		Jsonrpc: "2.0",
+		Method:  "completion/complete",
+		Params:  json.RawMessage(`{"ref":{"type":"ref/resource","uri":"specmill://schemas/{name}"},"argument":{"name":"name","value":"Pe"}}`),
+		ID:      1,
+	})
+	var result generator.CompleteResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to decode completion: %v", err)
+	}
+	if len(result.Completion.Values) != 1 || result.Completion.Values[0] != "Pet" {
+		t.Errorf("Expected completion Pet, got: %+v", result.Completion)
+	}
+
+	response = srv.handleRequest(context.Background(), &generator.MCPRequest{
+		Jsonrpc: "2.0",
+		Method:  "completion/complete",
+		Params:  json.RawMessage(`{"ref":{"type":"ref/prompt","name":"missing"},"argument":{"name":"x","value":""}}`),
+		ID:      2,
+	})
+	if response.Error == nil || response.Error.Code != codeInvalidParams {
+		t.Errorf("Expected invalid params for unknown prompt, got: %+v", response.Error)
+	}
+}
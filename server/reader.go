@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+const defaultMaxMessageSize = 16 << 20
+
+var errMessageTooLarge = errors.New("message too large")
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// messageReader splits a stdio stream into newline-delimited JSON-RPC
+// messages without a fixed buffer size. Messages longer than maxSize are
+// skipped up to the next newline and reported as errMessageTooLarge so the
+// session can continue.
+type messageReader struct {
+	r       *bufio.Reader
+	maxSize int
+}
+
+func newMessageReader(r io.Reader, maxSize int) *messageReader {
+	if maxSize <= 0 {
+		maxSize = defaultMaxMessageSize
+	}
+	return &messageReader{r: bufio.NewReader(r), maxSize: maxSize}
+}
+
+// next returns the next non-empty message with surrounding whitespace, CRLF
+// line endings and byte order marks removed, and invalid UTF-8 replaced.
+// It returns io.EOF once the stream is exhausted.
+func (m *messageReader) next() ([]byte, error) {
+	for {
+		line, err := m.readLine()
+		if err == errMessageTooLarge {
+			return nil, err
+		}
+
+		line = bytes.TrimPrefix(line, utf8BOM)
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			if !utf8.Valid(line) {
+				line = bytes.ToValidUTF8(line, []byte("�"))
+			}
+			return line, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (m *messageReader) readLine() ([]byte, error) {
+	var line []byte
+	tooLarge := false
+
+	for {
+		chunk, err := m.r.ReadSlice('\n')
+		if !tooLarge {
+			if len(line)+len(chunk) > m.maxSize+len("\r\n") {
+				tooLarge = true
+				line = nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+
+		switch err {
+		case bufio.ErrBufferFull:
+			continue
+		case nil:
+			if tooLarge {
+				return nil, errMessageTooLarge
+			}
+			return line, nil
+		default:
+			if tooLarge {
+				return nil, errMessageTooLarge
+			}
+			return line, err
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMessageReader(t *testing.T) {
+	large := `{"jsonrpc":"2.0","method":"tools/call","params":{"body":"` + strings.Repeat("x", 100000) + `"},"id":1}`
+	oversized := `{"data":"` + strings.Repeat("y", 300000) + `"}`
+
+	input := "\xEF\xBB\xBF" + `{"id":1}` + "\r\n" +
+		"\n" +
+		"   \r\n" +
+		large + "\n" +
+		oversized + "\n" +
+		`{"text":"bad ` + "\xff\xfe" + `"}` + "\r\n" +
+		`{"id":2}`
+
+	reader := newMessageReader(strings.NewReader(input), 200000)
+
+	expected := []struct {
+		message string
+		err     error
+	}{
+		{message: `{"id":1}`},
+		{message: large},
+		{err: errMessageTooLarge},
+		{message: `{"text":"bad �"}`},
+		{message: `{"id":2}`},
+		{err: io.EOF},
+	}
+
+	for i, exp := range expected {
+		message, err := reader.next()
+		if err != exp.err {
+			t.Fatalf("Message %d: expected error %v, got: %v", i, exp.err, err)
+		}
+		if string(message) != exp.message {
+			t.Errorf("Message %d: expected %.40q, got: %.40q", i, exp.message, string(message))
+		}
+	}
+}
+
+func TestHTTPMessageTooLarge(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml", WithMaxMessageSize(1024))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.HTTPHandler())
+	defer ts.Close()
+
+	body := `{"jsonrpc":"2.0","method":"initialize","params":{"pad":"` + strings.Repeat("x", 2048) + `"},"id":1}`
+	resp := postMCP(t, ts.URL, "", "application/json", body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for oversized message, got: %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"specmill/parser"
+)
+
+// ErrNoSpecSource is returned by Reload and Watch for servers that were not
+// created from a spec file or URL.
+var ErrNoSpecSource = errors.New("server has no spec source to reload")
+
+// isSpecURL reports whether source names a spec fetched over HTTP rather
+// than a local file.
+func isSpecURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// loadSpecSource reads a spec file or fetches a spec URL. For URLs, etag is
+// sent as If-None-Match and a nil document is returned when the upstream
+// reports it unchanged.
+func loadSpecSource(ctx context.Context, client *http.Client, source, etag string) ([]byte, string, error) {
+	if !isSpecURL(source) {
+		data, err := os.ReadFile(source)
+		return data, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "application/yaml, application/json;q=0.9, */*;q=0.8")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status fetching %s: %s", source, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, resp.Header.Get("ETag"), nil
+}
+
+// Reload re-reads the spec from the file or URL the server was created from
+// and, if it changed and still parses, atomically replaces the generated
+// tools, resources and prompts. Sessions are notified of the lists that
+// changed. On error the current set is kept. It reports whether the spec
+// was replaced.
+func (s *MCPServer) Reload(ctx context.Context) (bool, error) {
+	if s.source == "" {
+		return false, ErrNoSpecSource
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	data, etag, err := loadSpecSource(ctx, s.options.httpClient(), s.source, s.sourceETag)
+	if err != nil {
+		return false, fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+	s.sourceETag = etag
+	if data == nil || bytes.Equal(data, s.sourceData) {
+		return false, nil
+	}
+
+	spec, err := parser.ParseOpenAPISpecData(data)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+	gen, err := s.options.newGenerator(spec, data)
+	if err != nil {
+		return false, err
+	}
+
+	old := s.generator.Swap(gen)
+	s.sourceData = data
+
+	var changed []string
+	if !sameJSON(old.GetTools(), gen.GetTools()) {
+		changed = append(changed, "notifications/tools/list_changed")
+	}
+	if !sameJSON(old.GetResources(), gen.GetResources()) || !sameJSON(old.GetResourceTemplates(), gen.GetResourceTemplates()) {
+		changed = append(changed, "notifications/resources/list_changed")
+	}
+	if !sameJSON(old.GetPrompts(), gen.GetPrompts()) {
+		changed = append(changed, "notifications/prompts/list_changed")
+	}
+	s.notifyReady(changed...)
+
+	s.logf("reloaded OpenAPI spec from %s: %d tools", s.source, len(gen.GetTools()))
+	return true, nil
+}
+
+// Watch reloads the spec every interval until ctx is done or the server
+// shuts down. Failed reloads are logged and the previous spec stays in use.
+func (s *MCPServer) Watch(ctx context.Context, interval time.Duration) error {
+	if s.source == "" {
+		return ErrNoSpecSource
+	}
+	if interval <= 0 {
+		return fmt.Errorf("invalid reload interval: %s", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Reload(ctx); err != nil {
+				s.logf("keeping previous spec: %v", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.closing:
+			return nil
+		}
+	}
+}
+
+// notifyReady sends each notification to every initialized session.
+func (s *MCPServer) notifyReady(methods ...string) {
+	if len(methods) == 0 {
+		return
+	}
+
+	s.sessionsMu.Lock()
+	sessions := make([]*session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.sessionsMu.Unlock()
+
+	for _, sess := range sessions {
+		if sess.getState() != stateReady {
+			continue
+		}
+		for _, method := range methods {
+			sess.notify(method, nil)
+		}
+	}
+}
+
+func sameJSON(a, b any) bool {
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(da, db)
+}
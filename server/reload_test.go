@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func reloadTestSpec(operations string) []byte {
+	return []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Reload API", "version": "1.0.0"},
+		"paths": {"/pets": {` + operations + `}}
+	}`)
+}
+
+const (
+	reloadListOnly      = `"get": {"operationId": "listPets", "responses": {"200": {"description": "OK"}}}`
+	reloadListAndCreate = reloadListOnly + `,
+		"post": {"operationId": "createPet", "responses": {"201": {"description": "Created"}}}`
+)
+
+func readySessionFor(srv *MCPServer) *session {
+	sess := newSession()
+	sess.setState(stateReady)
+	srv.addSession(sess)
+	return sess
+}
+
+func drainEvents(sess *session) []string {
+	var events []string
+	for {
+		select {
+		case data := <-sess.events:
+			events = append(events, string(data))
+		default:
+			return events
+		}
+	}
+}
+
+func TestReloadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openapi.json")
+	if err := os.WriteFile(path, reloadTestSpec(reloadListOnly), 0o644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	srv, err := NewMCPServer(path)
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+	sess := readySessionFor(srv)
+	pending := newSession()
+	srv.addSession(pending)
+
+	changed, err := srv.Reload(context.Background())
+	if err != nil || changed {
+		t.Errorf("Expected unchanged spec to be skipped, got: %v, %v", changed, err)
+	}
+
+	if err := os.WriteFile(path, reloadTestSpec(reloadListAndCreate), 0o644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	changed, err = srv.Reload(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("Expected spec to be reloaded, got: %v, %v", changed, err)
+	}
+	if tools := srv.generator.Load().GetTools(); len(tools) != 2 {
+		t.Errorf("Expected 2 tools after reload, got: %d", len(tools))
+	}
+
+	events := drainEvents(sess)
+	expected := []string{"tools", "resources", "prompts"}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d notifications, got: %v", len(expected), events)
+	}
+	for i, list := range expected {
+		if !strings.Contains(events[i], "notifications/"+list+"/list_changed") {
+			t.Errorf("Expected %s list_changed notification, got: %s", list, events[i])
+		}
+	}
+	if events := drainEvents(pending); len(events) != 0 {
+		t.Errorf("Expected no notifications before initialization, got: %v", events)
+	}
+
+	if err := os.WriteFile(path, []byte("openapi: [broken"), 0o644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+	changed, err = srv.Reload(context.Background())
+	if err == nil || changed {
+		t.Errorf("Expected parse error, got: %v, %v", changed, err)
+	}
+	if tools := srv.generator.Load().GetTools(); len(tools) != 2 {
+		t.Errorf("Expected previous tools to be kept, got: %d", len(tools))
+	}
+	if events := drainEvents(sess); len(events) != 0 {
+		t.Errorf("Expected no notifications for a failed reload, got: %v", events)
+	}
+}
+
+func TestReloadURL(t *testing.T) {
+	var mu sync.Mutex
+	spec := reloadTestSpec(reloadListOnly)
+	etag := `"v1"`
+	notModified := 0
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	}))
+	defer upstream.Close()
+
+	srv, err := NewMCPServer(upstream.URL+"/openapi.json", WithHTTPClient(upstream.Client()))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+	if tools := srv.generator.Load().GetTools(); len(tools) != 1 {
+		t.Fatalf("Expected 1 tool, got: %d", len(tools))
+	}
+
+	changed, err := srv.Reload(context.Background())
+	if err != nil || changed {
+		t.Errorf("Expected unchanged spec to be skipped, got: %v, %v", changed, err)
+	}
+	if notModified != 1 {
+		t.Errorf("Expected conditional request, got %d not modified responses", notModified)
+	}
+
+	mu.Lock()
+	spec = reloadTestSpec(reloadListAndCreate)
+	etag = `"v2"`
+	mu.Unlock()
+
+	changed, err = srv.Reload(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("Expected spec to be reloaded, got: %v, %v", changed, err)
+	}
+	if tools := srv.generator.Load().GetTools(); len(tools) != 2 {
+		t.Errorf("Expected 2 tools after reload, got: %d", len(tools))
+	}
+}
+
+func TestReloadWithoutSource(t *testing.T) {
+	srv, err := NewMCPServerFromBytes(reloadTestSpec(reloadListOnly))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	if _, err := srv.Reload(context.Background()); !errors.Is(err, ErrNoSpecSource) {
+		t.Errorf("Expected ErrNoSpecSource, got: %v", err)
+	}
+	if err := srv.Watch(context.Background(), 0); !errors.Is(err, ErrNoSpecSource) {
+		t.Errorf("Expected ErrNoSpecSource, got: %v", err)
+	}
+}
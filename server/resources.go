@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"specmill/generator"
+)
+
+func (s *MCPServer) handleListResources(request *generator.MCPRequest) *generator.MCPResponse {
+	cursor, err := listCursor(request)
+	if err != nil {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params")
+	}
+
+	resources, next, err := paginate(s.generator.Load().GetResources(), cursor, s.pageSize, func(resource generator.MCPResource) string {
+		return resource.URI
+	})
+	if err != nil {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params: "+err.Error())
+	}
+
+	result := generator.ListResourcesResult{
+		Resources:  resources,
+		NextCursor: next,
+	}
+
+	resultBytes, _ := json.Marshal(result)
+	return &generator.MCPResponse{
+		Jsonrpc: "2.0",
+		Result:  json.RawMessage(resultBytes),
+		ID:      request.ID,
+	}
+}
+
+func (s *MCPServer) handleListResourceTemplates(request *generator.MCPRequest) *generator.MCPResponse {
+	cursor, err := listCursor(request)
+	if err != nil {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params")
+	}
+
+	templates, next, err := paginate(s.generator.Load().GetResourceTemplates(), cursor, s.pageSize, func(template generator.MCPResourceTemplate) string {
+		return template.URITemplate
+	})
+	if err != nil {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params: "+err.Error())
+	}
+
+	result := generator.ListResourceTemplatesResult{
+		ResourceTemplates: templates,
+		NextCursor:        next,
+	}
+
+	resultBytes, _ := json.Marshal(result)
+	return &generator.MCPResponse{
+		Jsonrpc: "2.0",
+		Result:  json.RawMessage(resultBytes),
+		ID:      request.ID,
+	}
+}
+
+func (s *MCPServer) handleReadResource(ctx context.Context, request *generator.MCPRequest) *generator.MCPResponse {
+	var params generator.ReadResourceParams
+	if err := json.Unmarshal(request.Params, &params); err != nil || params.URI == "" {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params")
+	}
+
+	result, err := s.generator.Load().ReadResource(ctx, params.URI)
+	if err != nil {
+		return resourceErrorResponse(request.ID, params.URI, err)
+	}
+
+	resultBytes, _ := json.Marshal(result)
+	return &generator.MCPResponse{
+		Jsonrpc: "2.0",
+		Result:  json.RawMessage(resultBytes),
+		ID:      request.ID,
+	}
+}
+
+func resourceErrorResponse(id interface{}, uri string, err error) *generator.MCPResponse {
+	switch {
+	case errors.Is(err, generator.ErrResourceNotFound):
+		response := errorResponse(id, codeResourceNotFound, "Resource not found")
+		response.Error.Data = map[string]string{"uri": uri}
+		return response
+	case errors.Is(err, generator.ErrInvalidResourceArguments):
+		return errorResponse(id, codeInvalidParams, err.Error())
+	default:
+		return errorResponse(id, codeInternalError, err.Error())
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"specmill/generator"
+)
+
+func TestResources(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	response := srv.handleRequest(context.Background(), &generator.MCPRequest{Jsonrpc: "2.0", Method: "resources/list", ID: 1})
+	var list generator.ListResourcesResult
+	if err := json.Unmarshal(response.Result, &list); err != nil {
+		t.Fatalf("Failed to decode resources: %v", err)
+	}
+	if len(list.Resources) == 0 || list.Resources[0].URI != "specmill://spec" {
+		t.Errorf("Expected spec resource first, got: %+v", list.Resources)
+	}
+
+	response = srv.handleRequest(context.Background(), &generator.MCPRequest{
+		Jsonrpc: "2.0",
+		Method:  "resources/read",
+		Params:  json.RawMessage(`{"uri":"specmill://spec"}`),
+		ID:      2,
+	})
+	var read generator.ReadResourceResult
+	if err := json.Unmarshal(response.Result, &read); err != nil {
+		t.Fatalf("Failed to decode resource: %v", err)
+	}
+	if len(read.Contents) != 1 || !strings.Contains(read.Contents[0].Text, "operationId: addPet") {
+		t.Errorf("Expected the raw spec document, got: %+v", read.Contents)
+	}
+
+	response = srv.handleRequest(context.Background(), &generator.MCPRequest{
+		Jsonrpc: "2.0",
+		Method:  "resources/read",
+		Params:  json.RawMessage(`{"uri":"specmill://schemas/Missing"}`),
+		ID:      3,
+	})
+	if response.Error == nil || response.Error.Code != codeResourceNotFound {
+		t.Errorf("Expected resource not found error, got: %+v", response.Error)
+	}
+
+	response = srv.handleRequest(context.Background(), &generator.MCPRequest{Jsonrpc: "2.0", Method: "resources/templates/list", ID: 4})
+	var templates generator.ListResourceTemplatesResult
+	if err := json.Unmarshal(response.Result, &templates); err != nil {
+		t.Fatalf("Failed to decode templates: %v", err)
+	}
+	if len(templates.ResourceTemplates) != 2 {
+		t.Errorf("Expected 2 resource templates, got: %+v", templates.ResourceTemplates)
+	}
+}
@@ -2,79 +2,379 @@ package server
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"specmill/generator"
 	"specmill/parser"
 )
 
+const (
+	defaultMaxConcurrency = 16
+	defaultMaxQueued      = 256
+)
+
 type MCPServer struct {
-	generator *generator.MCPGenerator
-	spec      *parser.OpenAPISpec
+	generator      atomic.Pointer[generator.MCPGenerator]
+	options        *options
+	slots          chan struct{}
+	maxMessageSize int
+	pageSize       int
+	logger         *log.Logger
+
+	// source is the path or URL the spec was loaded from, used to reload
+	// it. It is empty for servers created from bytes or a parsed spec.
+	source     string
+	reloadMu   sync.Mutex
+	sourceData []byte
+	sourceETag string
+
+	shutdownTimeout time.Duration
+	requests        requestTracker
+	closing         chan struct{}
+	closeOnce       sync.Once
+
+	sessionsMu sync.Mutex
+	sessions   map[string]*session
+}
+
+// NewMCPServer creates a server from a spec file or an http(s) URL.
+func NewMCPServer(specPath string, opts ...Option) (*MCPServer, error) {
+	o := newOptions(opts)
+
+	data, etag, err := loadSpecSource(context.Background(), o.httpClient(), specPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+
+	spec, err := parser.ParseOpenAPISpecData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+
+	s, err := newMCPServer(spec, data, o)
+	if err != nil {
+		return nil, err
+	}
+	s.source = specPath
+	s.sourceData = data
+	s.sourceETag = etag
+	return s, nil
 }
 
-func NewMCPServer(specPath string) (*MCPServer, error) {
-	spec, err := parser.ParseOpenAPISpec(specPath)
+// NewMCPServerFromBytes creates a server from a YAML or JSON spec held in
+// memory. The document is served unchanged as the spec resource.
+func NewMCPServerFromBytes(data []byte, opts ...Option) (*MCPServer, error) {
+	spec, err := parser.ParseOpenAPISpecData(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
 	}
 
-	gen := generator.NewMCPGenerator(spec)
-	if err := gen.GenerateTools(); err != nil {
-		return nil, fmt.Errorf("failed to generate tools: %w", err)
+	return newMCPServer(spec, data, newOptions(opts))
+}
+
+func NewMCPServerFromSpec(spec *parser.OpenAPISpec, opts ...Option) (*MCPServer, error) {
+	return newMCPServer(spec, nil, newOptions(opts))
+}
+
+func newMCPServer(spec *parser.OpenAPISpec, raw []byte, o *options) (*MCPServer, error) {
+	gen, err := o.newGenerator(spec, raw)
+	if err != nil {
+		return nil, err
 	}
 
-	return &MCPServer{
-		generator: gen,
-		spec:      spec,
-	}, nil
+	s := &MCPServer{
+		options:         o,
+		slots:           make(chan struct{}, o.maxConcurrency),
+		maxMessageSize:  o.maxMessageSize,
+		pageSize:        o.pageSize,
+		logger:          o.logger,
+		shutdownTimeout: o.shutdownTimeout,
+		closing:         make(chan struct{}),
+	}
+	s.generator.Store(gen)
+	return s, nil
 }
 
+// Start serves MCP over stdin and stdout.
 func (s *MCPServer) Start() error {
-	scanner := bufio.NewScanner(os.Stdin)
-	writer := bufio.NewWriter(os.Stdout)
+	return s.Serve(context.Background(), os.Stdin, os.Stdout)
+}
+
+type readResult struct {
+	line []byte
+	err  error
+}
+
+// Serve reads newline-delimited JSON-RPC messages from r and writes replies
+// to w until r is exhausted or ctx is cancelled. Requests are handled
+// concurrently, up to the server's concurrency limit. Requests beyond it
+// wait in a queue, where they can still be cancelled, and are rejected once
+// the queue is full, so that reading never pauses. Notifications, ping and
+// initialize are handled inline so that lifecycle and cancellation messages
+// take effect in order.
+//
+// When ctx is cancelled, Serve stops reading new messages and gives
+// in-flight requests up to the shutdown timeout to finish and write their
+// responses. Queued requests that have not started are answered with an
+// error. Serve then returns ctx.Err(), or ErrShutdownTimeout if requests had
+// to be cancelled.
+func (s *MCPServer) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	reader := newMessageReader(r, s.maxMessageSize)
+	out := &messageWriter{w: bufio.NewWriter(w)}
+	sess := newSession()
+	s.addSession(sess)
+	defer s.removeSession(sess)
+
+	// Handlers outlive ctx so that they can be drained on shutdown.
+	handlerCtx, cancelHandlers := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelHandlers()
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
+	stopped := make(chan struct{})
+	defer close(stopped)
+
+	lines := make(chan readResult)
+	go func() {
+		for {
+			line, err := reader.next()
+			select {
+			case lines <- readResult{line: line, err: err}:
+			case <-stopped:
+				return
+			}
+			if err != nil && err != errMessageTooLarge {
+				return
+			}
+		}
+	}()
+
+	// Server-initiated notifications, such as resource updates, are written
+	// alongside responses.
+	go func() {
+		for {
+			select {
+			case data := <-sess.events:
+				out.write(json.RawMessage(data))
+			case <-stopped:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	var readErr error
+	queued := make(chan struct{}, s.options.maxConcurrency+s.options.maxQueued)
+
+loop:
+	for {
+		if err := out.failed(); err != nil {
+			break
+		}
+
+		var next readResult
+		select {
+		case next = <-lines:
+		case <-ctx.Done():
+			break loop
+		}
+
+		line, err := next.line, next.err
+		if err == errMessageTooLarge {
+			s.logf("rejected message larger than %d bytes", s.maxMessageSize)
+			out.write(errorResponse(nil, codeInvalidRequest, fmt.Sprintf("Invalid Request: message exceeds %d bytes", s.maxMessageSize)))
+			continue
+		}
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
+			}
+			break
+		}
+
+		if isBatch(line) {
+			wg.Add(1)
+			go func(data []byte) {
+				defer wg.Done()
+
+				responses, errResp := s.handleBatch(handlerCtx, sess, data)
+				switch {
+				case errResp != nil:
+					out.write(errResp)
+				case len(responses) > 0:
+					out.write(responses)
+				}
+			}(line)
 			continue
 		}
 
 		var request generator.MCPRequest
-		if err := json.Unmarshal([]byte(line), &request); err != nil {
-			s.writeError(writer, nil, -32700, "Parse error")
+		if errResp := decodeRequest(line, &request); errResp != nil {
+			out.write(errResp)
 			continue
 		}
 
-		response := s.handleRequest(&request)
-		if err := s.writeResponse(writer, response); err != nil {
-			return fmt.Errorf("failed to write response: %w", err)
+		if request.ID == nil || request.Method == "initialize" || request.Method == "ping" {
+			if response := s.handleMessage(handlerCtx, sess, &request); response != nil {
+				out.write(response)
+			}
+			continue
 		}
+
+		// Slots are acquired by the handler so that cancellations and pings
+		// are still read while every slot is taken.
+		select {
+		case queued <- struct{}{}:
+		default:
+			out.write(errorResponse(request.ID, codeInternalError, "Server busy: too many queued requests"))
+			continue
+		}
+		wg.Add(1)
+		go func(request generator.MCPRequest) {
+			defer wg.Done()
+			defer func() { <-queued }()
+
+			if response := s.handleQueuedMessage(handlerCtx, ctx, sess, &request); response != nil {
+				out.write(response)
+			}
+		}(request)
+	}
+
+	if ctx.Err() == nil {
+		wg.Wait()
+	} else if !s.drain(&wg, cancelHandlers) {
+		return fmt.Errorf("%w: in-flight requests were cancelled", ErrShutdownTimeout)
+	}
+
+	if err := out.failed(); err != nil {
+		return fmt.Errorf("failed to write response: %w", err)
+	}
+
+	if readErr != nil {
+		return fmt.Errorf("read error: %w", readErr)
+	}
+
+	return ctx.Err()
+}
+
+// drain waits up to the shutdown timeout for wg, cancelling the remaining
+// handlers if it expires. It reports whether every handler finished in time.
+func (s *MCPServer) drain(wg *sync.WaitGroup, cancel context.CancelFunc) bool {
+	s.logf("shutting down, waiting up to %s for in-flight requests", s.shutdownTimeout)
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	timer := time.NewTimer(s.shutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-finished:
+		return true
+	case <-timer.C:
+		cancel()
+		<-finished
+		return false
+	}
+}
+
+func (s *MCPServer) logf(format string, args ...any) {
+	if s.logger != nil {
+		s.logger.Printf(format, args...)
+	}
+}
+
+func (s *MCPServer) acquireSlot(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// waitForSlot is acquireSlot for a request that is also abandoned when queue
+// is done, such as when the server shuts down before it could start.
+func (s *MCPServer) waitForSlot(ctx, queue context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-queue.Done():
+		return errShuttingDown
 	}
+}
+
+func (s *MCPServer) releaseSlot() {
+	<-s.slots
+}
 
-	if err := scanner.Err(); err != nil && err != io.EOF {
-		return fmt.Errorf("scanner error: %w", err)
+// messageWriter serializes writes of JSON-RPC messages from concurrent
+// handlers and remembers the first write failure.
+type messageWriter struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	err error
+}
+
+func (m *messageWriter) write(message any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return
 	}
+	m.err = writeMessage(m.w, message)
+}
 
-	return nil
+func (m *messageWriter) failed() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
 }
 
-func (s *MCPServer) handleRequest(request *generator.MCPRequest) *generator.MCPResponse {
+func (s *MCPServer) handleRequest(ctx context.Context, request *generator.MCPRequest) *generator.MCPResponse {
 	switch request.Method {
 	case "initialize":
 		return s.handleInitialize(request)
+	case "ping":
+		return s.handlePing(request)
 	case "tools/list":
-		return s.handleListTools(request)
+		return s.handleListTools(ctx, request)
 	case "tools/call":
-		return s.handleCallTool(request)
+		return s.handleCallTool(ctx, request)
+	case "resources/list":
+		return s.handleListResources(request)
+	case "resources/templates/list":
+		return s.handleListResourceTemplates(request)
+	case "resources/read":
+		return s.handleReadResource(ctx, request)
+	case "resources/subscribe":
+		return s.handleSubscribe(ctx, request)
+	case "resources/unsubscribe":
+		return s.handleUnsubscribe(ctx, request)
+	case "prompts/list":
+		return s.handleListPrompts(request)
+	case "prompts/get":
+		return s.handleGetPrompt(request)
+	case "completion/complete":
+		return s.handleComplete(ctx, request)
+	case "logging/setLevel":
+		return s.handleSetLevel(ctx, request)
 	default:
 		return &generator.MCPResponse{
 			Jsonrpc: "2.0",
 			Error: &generator.MCPError{
-				Code:    -32601,
+				Code:    codeMethodNotFound,
 				Message: "Method not found",
 			},
 			ID: request.ID,
@@ -83,16 +383,28 @@ func (s *MCPServer) handleRequest(request *generator.MCPRequest) *generator.MCPR
 }
 
 func (s *MCPServer) handleInitialize(request *generator.MCPRequest) *generator.MCPResponse {
+	version, err := negotiateProtocolVersion(request.Params)
+	if err != nil {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params")
+	}
+
 	result := generator.InitializeResult{
-		ProtocolVersion: "2025-06-18",
+		ProtocolVersion: version,
 		Capabilities: generator.Capabilities{
-			Tools: map[string]any{},
+			Tools:     map[string]any{"listChanged": true},
+			Resources: map[string]any{"subscribe": true, "listChanged": true},
+			Prompts:   map[string]any{"listChanged": true},
+			Logging:   map[string]any{},
 		},
 		ServerInfo: generator.ServerInfo{
 			Name:    "specmill",
 			Version: "1.0.0",
 		},
 	}
+	// The completions capability was introduced in 2025-03-26.
+	if version >= "2025-03-26" {
+		result.Capabilities.Completions = map[string]any{}
+	}
 
 	resultBytes, _ := json.Marshal(result)
 	return &generator.MCPResponse{
@@ -102,9 +414,22 @@ func (s *MCPServer) handleInitialize(request *generator.MCPRequest) *generator.M
 	}
 }
 
-func (s *MCPServer) handleListTools(request *generator.MCPRequest) *generator.MCPResponse {
+func (s *MCPServer) handleListTools(ctx context.Context, request *generator.MCPRequest) *generator.MCPResponse {
+	cursor, err := listCursor(request)
+	if err != nil {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params")
+	}
+
+	tools, next, err := paginate(s.generator.Load().GetTools(), cursor, s.pageSize, func(tool generator.MCPTool) string {
+		return tool.Name
+	})
+	if err != nil {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params: "+err.Error())
+	}
+
 	result := generator.ListToolsResult{
-		Tools: s.generator.GetTools(),
+		Tools:      toolsForVersion(tools, protocolVersion(ctx)),
+		NextCursor: next,
 	}
 
 	resultBytes, _ := json.Marshal(result)
@@ -115,32 +440,41 @@ func (s *MCPServer) handleListTools(request *generator.MCPRequest) *generator.MC
 	}
 }
 
-func (s *MCPServer) handleCallTool(request *generator.MCPRequest) *generator.MCPResponse {
+func (s *MCPServer) handleCallTool(ctx context.Context, request *generator.MCPRequest) *generator.MCPResponse {
 	var params generator.CallToolParams
 	if err := json.Unmarshal(request.Params, &params); err != nil {
 		return &generator.MCPResponse{
 			Jsonrpc: "2.0",
 			Error: &generator.MCPError{
-				Code:    -32602,
+				Code:    codeInvalidParams,
 				Message: "Invalid params",
 			},
 			ID: request.ID,
 		}
 	}
 
-	result, err := s.generator.ExecuteTool(params.Name, params.Arguments)
+	ctx = generator.WithLog(ctx, logNotifier(ctx))
+	if !hasOutputSchemas(protocolVersion(ctx)) {
+		ctx = generator.WithoutOutputSchemas(ctx)
+	}
+	if params.Meta != nil && params.Meta.ProgressToken != nil {
+		ctx = generator.WithProgress(ctx, progressNotifier(ctx, params.Meta.ProgressToken))
+	}
+
+	result, err := s.generator.Load().ExecuteTool(ctx, params.Name, params.Arguments)
 	if err != nil {
+		s.logf("tool %s failed: %v", params.Name, err)
 		return &generator.MCPResponse{
 			Jsonrpc: "2.0",
 			Error: &generator.MCPError{
-				Code:    -32603,
+				Code:    codeInternalError,
 				Message: err.Error(),
 			},
 			ID: request.ID,
 		}
 	}
 
-	resultBytes, _ := json.Marshal(result)
+	resultBytes, _ := json.Marshal(callResultForVersion(result, protocolVersion(ctx)))
 	return &generator.MCPResponse{
 		Jsonrpc: "2.0",
 		Result:  json.RawMessage(resultBytes),
@@ -148,8 +482,24 @@ func (s *MCPServer) handleCallTool(request *generator.MCPRequest) *generator.MCP
 	}
 }
 
+func errorResponse(id interface{}, code int, message string) *generator.MCPResponse {
+	return &generator.MCPResponse{
+		Jsonrpc: "2.0",
+		Error: &generator.MCPError{
+			Code:    code,
+			Message: message,
+		},
+		ID: id,
+	}
+}
+
 func (s *MCPServer) writeResponse(w *bufio.Writer, response *generator.MCPResponse) error {
-	data, err := json.Marshal(response)
+	return writeMessage(w, response)
+}
+
+// writeMessage writes a JSON-RPC message or batch as a single line.
+func writeMessage(w *bufio.Writer, message any) error {
+	data, err := json.Marshal(message)
 	if err != nil {
 		return err
 	}
@@ -164,15 +514,3 @@ func (s *MCPServer) writeResponse(w *bufio.Writer, response *generator.MCPRespon
 
 	return w.Flush()
 }
-
-func (s *MCPServer) writeError(w *bufio.Writer, id interface{}, code int, message string) {
-	response := &generator.MCPResponse{
-		Jsonrpc: "2.0",
-		Error: &generator.MCPError{
-			Code:    code,
-			Message: message,
-		},
-		ID: id,
-	}
-	_ = s.writeResponse(w, response)
-}
\ No newline at end of file
@@ -3,6 +3,7 @@ package server
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"strings"
 	"testing"
@@ -139,7 +140,7 @@ func TestMCPServer(t *testing.T) {
 			}
 
 			// Handle request
-			response := srv.handleRequest(&request)
+			response := srv.handleRequest(context.Background(), &request)
 
 			// Validate response
 			tt.validate(t, response)
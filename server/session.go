@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type session struct {
+	id     string
+	events chan []byte
+	done   chan struct{}
+
+	mu        sync.Mutex
+	state     sessionState
+	version   string
+	logLevel  string
+	streaming bool
+	closed    bool
+	inflight  map[string]*inflightCall
+
+	// expiry ends the session once it has been idle for idleTimeout; active
+	// counts the requests and streams keeping it alive.
+	expiry      *time.Timer
+	idleTimeout time.Duration
+	active      int
+
+	subscriptions map[string]context.CancelFunc
+}
+
+type inflightCall struct {
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+func newSession() *session {
+	return &session{
+		id:     newSessionID(),
+		events: make(chan []byte, 64),
+		done:   make(chan struct{}),
+	}
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate session id: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// notification encodes a server-initiated JSON-RPC notification.
+func notification(method string, params any) ([]byte, error) {
+	message := map[string]any{
+		"jsonrpc": "2.0",
+		"method":  method,
+	}
+	if params != nil {
+		message["params"] = params
+	}
+	return json.Marshal(message)
+}
+
+// notify queues a server-initiated JSON-RPC notification for delivery on the
+// session's event stream. Messages are dropped if the queue is full.
+func (s *session) notify(method string, params any) {
+	data, err := notification(method, params)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.events <- data:
+	default:
+	}
+}
+
+// deliver queues a message that must not be dropped, such as a response on
+// a legacy SSE session, waiting for room on the stream until the session
+// ends.
+func (s *session) deliver(message any) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case s.events <- data:
+		return nil
+	case <-s.done:
+		return fmt.Errorf("session %s closed", s.id)
+	}
+}
+
+func (s *session) getState() sessionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+func (s *session) setState(state sessionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+}
+
+func (s *session) getLogLevel() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.logLevel == "" {
+		return defaultLogLevel
+	}
+	return s.logLevel
+}
+
+func (s *session) setLogLevel(level string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logLevel = level
+}
+
+func (s *session) getProtocolVersion() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+func (s *session) setProtocolVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version = version
+}
+
+// track registers an in-flight request so that it can be cancelled by id.
+func (s *session) track(id interface{}, cancel context.CancelFunc) *inflightCall {
+	call := &inflightCall{cancel: cancel}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inflight == nil {
+		s.inflight = make(map[string]*inflightCall)
+	}
+	s.inflight[requestKey(id)] = call
+	return call
+}
+
+// untrack removes a finished request and reports whether it was cancelled by
+// the client, in which case it must not be answered.
+func (s *session) untrack(id interface{}, call *inflightCall) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := requestKey(id)
+	if s.inflight[key] == call {
+		delete(s.inflight, key)
+	}
+	return call.cancelled
+}
+
+func (s *session) cancel(id interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if call, ok := s.inflight[requestKey(id)]; ok {
+		call.cancelled = true
+		call.cancel()
+	}
+}
+
+func requestKey(id interface{}) string {
+	data, _ := json.Marshal(id)
+	return string(data)
+}
+
+// subscribe records a resource subscription, replacing any previous one for
+// the same URI. cancel stops the subscription's poller.
+func (s *session) subscribe(uri string, cancel context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if previous, ok := s.subscriptions[uri]; ok {
+		previous()
+	}
+	if s.subscriptions == nil {
+		s.subscriptions = make(map[string]context.CancelFunc)
+	}
+	s.subscriptions[uri] = cancel
+}
+
+func (s *session) unsubscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.subscriptions[uri]; ok {
+		cancel()
+		delete(s.subscriptions, uri)
+	}
+}
+
+// expireWhenIdle calls expire once the session has gone d without an active
+// request or stream.
+func (s *session) expireWhenIdle(d time.Duration, expire func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.idleTimeout = d
+	s.expiry = time.AfterFunc(d, expire)
+	if s.active > 0 {
+		s.expiry.Stop()
+	}
+}
+
+// acquire marks the session as in use until the matching release, holding
+// off its expiry.
+func (s *session) acquire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active++
+	if s.expiry != nil {
+		s.expiry.Stop()
+	}
+}
+
+func (s *session) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active--
+	if s.active == 0 && s.expiry != nil && !s.closed {
+		s.expiry.Reset(s.idleTimeout)
+	}
+}
+
+func (s *session) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+	if s.expiry != nil {
+		s.expiry.Stop()
+	}
+	for _, call := range s.inflight {
+		call.cancel()
+	}
+	for _, cancel := range s.subscriptions {
+		cancel()
+	}
+}
+
+type sessionKey struct{}
+
+func withSession(ctx context.Context, sess *session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sess)
+}
+
+func sessionFromContext(ctx context.Context) *session {
+	sess, _ := ctx.Value(sessionKey{}).(*session)
+	return sess
+}
+
+type notifyFunc func(method string, params any)
+
+type notifierKey struct{}
+
+// withNotifier sends the notifications related to the request in ctx, such
+// as its progress and log messages, with notify instead of queueing them on
+// the session's event stream.
+func withNotifier(ctx context.Context, notify notifyFunc) context.Context {
+	return context.WithValue(ctx, notifierKey{}, notify)
+}
+
+// requestNotifier returns the function sending notifications related to the
+// request in ctx, or nil when there is no session to send them to.
+func requestNotifier(ctx context.Context) notifyFunc {
+	if notify, ok := ctx.Value(notifierKey{}).(notifyFunc); ok {
+		return notify
+	}
+	if sess := sessionFromContext(ctx); sess != nil {
+		return sess.notify
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultShutdownTimeout = 10 * time.Second
+
+// ErrShutdownTimeout is returned when in-flight requests had to be aborted
+// because they did not finish within the shutdown deadline.
+var ErrShutdownTimeout = errors.New("shutdown deadline exceeded")
+
+// errShuttingDown ends the wait of requests still queued for a worker when
+// shutdown begins.
+var errShuttingDown = errors.New("server is shutting down")
+
+// requestTracker counts requests being handled by the HTTP transports so
+// that shutdown can stop accepting new ones and wait for the rest.
+type requestTracker struct {
+	mu       sync.Mutex
+	active   int
+	draining bool
+	idle     chan struct{}
+}
+
+// begin registers a new request, reporting false once draining has started.
+func (t *requestTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.active++
+	return true
+}
+
+// add registers work spawned by a request that is still registered, so it
+// is accepted even while draining.
+func (t *requestTracker) add() {
+	t.mu.Lock()
+	t.active++
+	t.mu.Unlock()
+}
+
+func (t *requestTracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active--
+	if t.active == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// drain rejects new requests and waits until the active ones finish or ctx
+// is done.
+func (t *requestTracker) drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	if t.active == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown gracefully stops the HTTP transports of a server whose handlers
+// are mounted on an external http.Server: new requests are rejected,
+// in-flight ones are drained until ctx is done, and open event streams are
+// then closed. Requests still running at the deadline are cancelled and
+// ErrShutdownTimeout is returned.
+func (s *MCPServer) Shutdown(ctx context.Context) error {
+	err := s.requests.drain(ctx)
+	s.closeOnce.Do(func() { close(s.closing) })
+	if err != nil {
+		s.closeSessions()
+		return fmt.Errorf("%w: %v", ErrShutdownTimeout, err)
+	}
+	return nil
+}
+
+// closeSessions ends every session, cancelling its in-flight calls.
+func (s *MCPServer) closeSessions() {
+	s.sessionsMu.Lock()
+	sessions := s.sessions
+	s.sessions = nil
+	s.sessionsMu.Unlock()
+
+	for _, sess := range sessions {
+		sess.close()
+	}
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const readySession = `{"jsonrpc":"2.0","method":"initialize","params":{"protocolVersion":"2025-06-18"},"id":1}
+{"jsonrpc":"2.0","method":"notifications/initialized"}
+`
+
+func TestServeGracefulShutdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		delay   time.Duration
+		reply   string
+		err     error
+	}{
+		{
+			name:    "Drains in-flight call",
+			timeout: 2 * time.Second,
+			delay:   100 * time.Millisecond,
+			reply:   `"result"`,
+			err:     context.Canceled,
+		},
+		{
+			name:    "Cancels call past deadline",
+			timeout: 50 * time.Millisecond,
+			delay:   5 * time.Second,
+			reply:   `"error"`,
+			err:     ErrShutdownTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				select {
+				case <-time.After(tt.delay):
+					w.Write([]byte(`{"ok":true}`))
+				case <-r.Context().Done():
+				}
+			}))
+			defer upstream.Close()
+
+			srv, err := NewMCPServer(writeTestSpec(t, upstream.URL), WithShutdownTimeout(tt.timeout))
+			if err != nil {
+				t.Fatalf("Failed to create MCP server: %v", err)
+			}
+
+			inR, inW := io.Pipe()
+			defer inW.Close()
+			outR, outW := io.Pipe()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- srv.Serve(ctx, inR, outW)
+				outW.Close()
+			}()
+
+			go inW.Write([]byte(readySession + `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slowCall","arguments":{}},"id":2}` + "\n"))
+
+			output := make(chan string, 1)
+			go func() {
+				data, _ := io.ReadAll(bufio.NewReader(outR))
+				output <- string(data)
+			}()
+
+			<-started
+			cancel()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Expected error %v, got: %v", tt.err, err)
+				}
+			case <-time.After(3 * time.Second):
+				t.Fatal("Serve did not return after shutdown")
+			}
+
+			var reply string
+			for _, line := range strings.Split(<-output, "\n") {
+				if strings.Contains(line, `"id":2`) {
+					reply = line
+				}
+			}
+			if !strings.Contains(reply, tt.reply) {
+				t.Errorf("Expected call reply with %s, got: %q", tt.reply, reply)
+			}
+		})
+	}
+}
+
+func TestHTTPShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	srv, err := NewMCPServer(writeTestSpec(t, upstream.URL))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.HTTPHandler())
+	defer ts.Close()
+
+	resp := postMCP(t, ts.URL, "", "application/json", `{"jsonrpc":"2.0","method":"initialize","params":{},"id":1}`)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(sessionHeader)
+	resp = postMCP(t, ts.URL, sessionID, "application/json", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp.Body.Close()
+
+	inflight := make(chan int, 1)
+	go func() {
+		resp := postMCP(t, ts.URL, sessionID, "application/json", `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"slowCall","arguments":{}},"id":2}`)
+		resp.Body.Close()
+		inflight <- resp.StatusCode
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		shutdown <- srv.Shutdown(ctx)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp := postMCP(t, ts.URL, sessionID, "application/json", `{"jsonrpc":"2.0","method":"ping","id":3}`)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 503 while shutting down, got: %d", resp.StatusCode)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(release)
+
+	if status := <-inflight; status != http.StatusOK {
+		t.Errorf("Expected in-flight call to complete with 200, got: %d", status)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Expected clean shutdown, got: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"specmill/generator"
+)
+
+// SSEHandler returns a handler implementing the event stream half of the
+// 2024-11-05 HTTP+SSE transport. Each GET opens a session and announces the
+// endpoint the client must POST its messages to.
+func (s *MCPServer) SSEHandler() http.Handler {
+	return http.HandlerFunc(s.serveSSE)
+}
+
+// MessagesHandler returns a handler accepting client messages for sessions
+// opened through SSEHandler. Responses are delivered on the event stream.
+func (s *MCPServer) MessagesHandler() http.Handler {
+	return http.HandlerFunc(s.serveMessages)
+}
+
+func (s *MCPServer) serveSSE(w http.ResponseWriter, r *http.Request) {
+	if !validOrigin(r) {
+		http.Error(w, "Forbidden origin", http.StatusForbidden)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sess := newSession()
+	s.addSession(sess)
+	defer s.removeSession(sess)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	endpoint := strings.TrimSuffix(r.URL.Path, "/sse") + "/messages?sessionId=" + sess.id
+	if err := writeSSE(w, "endpoint", []byte(endpoint)); err != nil {
+		return
+	}
+
+	s.streamEvents(w, r, sess)
+}
+
+func (s *MCPServer) serveMessages(w http.ResponseWriter, r *http.Request) {
+	if !validOrigin(r) {
+		http.Error(w, "Forbidden origin", http.StatusForbidden)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("sessionId")
+	if id == "" {
+		http.Error(w, "Missing sessionId", http.StatusBadRequest)
+		return
+	}
+
+	sess := s.findSession(id)
+	if sess == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if !s.requests.begin() {
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer s.requests.done()
+
+	data, err := s.readHTTPMessage(w, r)
+	if err != nil {
+		return
+	}
+
+	if isBatch(data) {
+		w.WriteHeader(http.StatusAccepted)
+		s.requests.add()
+		go func() {
+			defer s.requests.done()
+
+			responses, errResp := s.handleBatch(context.Background(), sess, data)
+			switch {
+			case errResp != nil:
+				_ = sess.deliver(errResp)
+			case len(responses) > 0:
+				_ = sess.deliver(responses)
+			}
+		}()
+		return
+	}
+
+	var request generator.MCPRequest
+	if errResp := decodeRequest(data, &request); errResp != nil {
+		_ = sess.deliver(errResp)
+		http.Error(w, "Invalid message", http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+
+	if request.ID == nil {
+		s.handleMessage(context.Background(), sess, &request)
+		return
+	}
+
+	s.requests.add()
+	go func() {
+		defer s.requests.done()
+
+		ctx := context.Background()
+		if err := s.acquireSlot(ctx); err != nil {
+			return
+		}
+		defer s.releaseSlot()
+
+		if response := s.handleMessage(ctx, sess, &request); response != nil {
+			_ = sess.deliver(response)
+		}
+	}()
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"specmill/generator"
+)
+
+func readSSEEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	t.Helper()
+
+	var event, data string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "" && data != "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data += strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestLegacySSETransport(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/sse", srv.SSEHandler())
+	mux.Handle("/messages", srv.MessagesHandler())
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	stream, err := http.Get(ts.URL + "/sse")
+	if err != nil {
+		t.Fatalf("Failed to open SSE stream: %v", err)
+	}
+	defer stream.Body.Close()
+	reader := bufio.NewReader(stream.Body)
+
+	event, endpoint := readSSEEvent(t, reader)
+	if event != "endpoint" {
+		t.Fatalf("Expected endpoint event, got: %s", event)
+	}
+	if !strings.HasPrefix(endpoint, "/messages?sessionId=") {
+		t.Fatalf("Unexpected endpoint: %s", endpoint)
+	}
+
+	post := func(body string) int {
+		resp, err := http.Post(ts.URL+endpoint, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to post message: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := post(`{"jsonrpc":"2.0","method":"initialize","params":{"protocolVersion":"2024-11-05"},"id":1}`); status != http.StatusAccepted {
+		t.Fatalf("Expected 202 for message, got: %d", status)
+	}
+
+	event, data := readSSEEvent(t, reader)
+	if event != "message" {
+		t.Fatalf("Expected message event, got: %s", event)
+	}
+	var response generator.MCPResponse
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.ID != float64(1) || response.Error != nil {
+		t.Errorf("Expected initialize result for id 1, got: %s", data)
+	}
+
+	if status := post(`{"jsonrpc":"2.0","method":"notifications/initialized"}`); status != http.StatusAccepted {
+		t.Errorf("Expected 202 for notification, got: %d", status)
+	}
+
+	if status := post(`{"jsonrpc":"2.0","method":"tools/list","id":2}`); status != http.StatusAccepted {
+		t.Fatalf("Expected 202 for message, got: %d", status)
+	}
+	_, data = readSSEEvent(t, reader)
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.ID != float64(2) || len(response.Result) == 0 {
+		t.Errorf("Expected tools/list result for id 2, got: %s", data)
+	}
+
+	resp, err := http.Post(ts.URL+"/messages?sessionId=unknown", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("Failed to post message: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown session, got: %d", resp.StatusCode)
+	}
+}
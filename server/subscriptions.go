@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"specmill/generator"
+)
+
+func (s *MCPServer) handleSubscribe(ctx context.Context, request *generator.MCPRequest) *generator.MCPResponse {
+	sess := sessionFromContext(ctx)
+	if sess == nil {
+		return errorResponse(request.ID, codeInvalidRequest, "Invalid Request: subscriptions require a session")
+	}
+
+	var params generator.ReadResourceParams
+	if err := json.Unmarshal(request.Params, &params); err != nil || params.URI == "" {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params")
+	}
+
+	// The first poll validates the URI and records the baseline that later
+	// polls are compared against.
+	state, _, err := s.generator.Load().PollResource(ctx, params.URI, generator.ResourceState{})
+	if err != nil {
+		return resourceErrorResponse(request.ID, params.URI, err)
+	}
+
+	pollCtx, cancel := context.WithCancel(context.Background())
+	sess.subscribe(params.URI, cancel)
+	if interval := s.generator.Load().PollInterval(params.URI); interval > 0 {
+		go s.pollResource(pollCtx, sess, params.URI, state, interval)
+	}
+
+	return &generator.MCPResponse{
+		Jsonrpc: "2.0",
+		Result:  json.RawMessage(`{}`),
+		ID:      request.ID,
+	}
+}
+
+func (s *MCPServer) handleUnsubscribe(ctx context.Context, request *generator.MCPRequest) *generator.MCPResponse {
+	sess := sessionFromContext(ctx)
+	if sess == nil {
+		return errorResponse(request.ID, codeInvalidRequest, "Invalid Request: subscriptions require a session")
+	}
+
+	var params generator.ReadResourceParams
+	if err := json.Unmarshal(request.Params, &params); err != nil || params.URI == "" {
+		return errorResponse(request.ID, codeInvalidParams, "Invalid params")
+	}
+
+	sess.unsubscribe(params.URI)
+
+	return &generator.MCPResponse{
+		Jsonrpc: "2.0",
+		Result:  json.RawMessage(`{}`),
+		ID:      request.ID,
+	}
+}
+
+// pollResource re-fetches a subscribed resource every interval and notifies
+// the session when its contents change. Failed polls are logged and retried
+// on the next tick.
+func (s *MCPServer) pollResource(ctx context.Context, sess *session, uri string, state generator.ResourceState, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		case <-sess.done:
+			return
+		case <-s.closing:
+			return
+		}
+
+		next, changed, err := s.generator.Load().PollResource(ctx, uri, state)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logf("polling %s failed: %v", uri, err)
+			continue
+		}
+		state = next
+
+		if changed {
+			sess.notify("notifications/resources/updated", map[string]string{"uri": uri})
+		}
+	}
+}
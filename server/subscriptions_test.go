@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"specmill/generator"
+)
+
+func TestResourceSubscription(t *testing.T) {
+	var version atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if version.Load() == 0 {
+			w.Write([]byte(`{"status":"pending"}`))
+		} else {
+			w.Write([]byte(`{"status":"done"}`))
+		}
+	}))
+	defer upstream.Close()
+
+	cfg := &generator.Config{
+		PollInterval: 20 * time.Millisecond,
+		Operations:   map[string]generator.OperationConfig{"listPets": {Resource: true}},
+	}
+	srv, err := NewMCPServerFromBytes(testSpecData(upstream.URL), WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	defer outR.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Serve(ctx, inR, outW)
+	defer inW.Close()
+
+	lines := make(chan string, 16)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for message")
+			return ""
+		}
+	}
+
+	go inW.Write([]byte(readySession + `{"jsonrpc":"2.0","method":"resources/subscribe","params":{"uri":"specmill://api/pets"},"id":2}` + "\n"))
+	next()
+	if line := next(); !strings.Contains(line, `"id":2`) || !strings.Contains(line, `"result":{}`) {
+		t.Fatalf("Expected subscribe result, got: %s", line)
+	}
+
+	version.Store(1)
+	if line := next(); !strings.Contains(line, `"method":"notifications/resources/updated"`) || !strings.Contains(line, `"uri":"specmill://api/pets"`) {
+		t.Errorf("Expected resource updated notification, got: %s", line)
+	}
+
+	go inW.Write([]byte(`{"jsonrpc":"2.0","method":"resources/unsubscribe","params":{"uri":"specmill://api/pets"},"id":3}` + "\n"))
+	if line := next(); !strings.Contains(line, `"id":3`) {
+		t.Errorf("Expected unsubscribe result, got: %s", line)
+	}
+
+	go inW.Write([]byte(`{"jsonrpc":"2.0","method":"resources/subscribe","params":{"uri":"specmill://api/missing"},"id":4}` + "\n"))
+	if line := next(); !strings.Contains(line, `"code":-32002`) {
+		t.Errorf("Expected resource not found error, got: %s", line)
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"specmill/generator"
+)
+
+const latestProtocolVersion = "2025-06-18"
+
+// supportedProtocolVersions lists the MCP revisions the server can speak,
+// newest first.
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+type protocolVersionKey struct{}
+
+func withProtocolVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, protocolVersionKey{}, version)
+}
+
+// protocolVersion returns the version negotiated for the request's session,
+// defaulting to the latest revision.
+func protocolVersion(ctx context.Context) string {
+	if version, ok := ctx.Value(protocolVersionKey{}).(string); ok && version != "" {
+		return version
+	}
+	return latestProtocolVersion
+}
+
+func isSupportedProtocolVersion(version string) bool {
+	for _, supported := range supportedProtocolVersions {
+		if version == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateProtocolVersion answers with the client's requested version when
+// it is supported and with the latest supported version otherwise.
+func negotiateProtocolVersion(params json.RawMessage) (string, error) {
+	if len(params) == 0 {
+		return latestProtocolVersion, nil
+	}
+
+	var initParams generator.InitializeParams
+	if err := json.Unmarshal(params, &initParams); err != nil {
+		return "", err
+	}
+
+	if isSupportedProtocolVersion(initParams.ProtocolVersion) {
+		return initParams.ProtocolVersion, nil
+	}
+	return latestProtocolVersion, nil
+}
+
+// hasOutputSchemas reports whether clients of the given protocol version are
+// sent tool output schemas.
+func hasOutputSchemas(version string) bool {
+	return version >= "2025-06-18"
+}
+
+// toolsForVersion strips tool fields introduced after the given protocol
+// version: annotations arrived in 2025-03-26, titles and output schemas in
+// 2025-06-18.
+func toolsForVersion(tools []generator.MCPTool, version string) []generator.MCPTool {
+	if hasOutputSchemas(version) {
+		return tools
+	}
+
+	adapted := make([]generator.MCPTool, len(tools))
+	for i, tool := range tools {
+		tool.Title = ""
+		tool.OutputSchema = nil
+		if version < "2025-03-26" {
+			tool.Annotations = nil
+		}
+		adapted[i] = tool
+	}
+	return adapted
+}
+
+// callResultForVersion strips structured content, introduced in 2025-06-18,
+// from results sent to older clients.
+func callResultForVersion(result *generator.CallToolResult, version string) *generator.CallToolResult {
+	if hasOutputSchemas(version) || result.StructuredContent == nil {
+		return result
+	}
+
+	adapted := *result
+	adapted.StructuredContent = nil
+	return &adapted
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"specmill/generator"
+)
+
+func TestProtocolVersionNegotiation(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/name") {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("Rex"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "name": "Rex"}`))
+	}))
+	defer upstream.Close()
+
+	spec := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: ` + upstream.URL + `
+paths:
+  /pets/{petId}:
+    get:
+      operationId: getPet
+      summary: Get a pet
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id:
+                    type: integer
+                  name:
+                    type: string
+  /pets/{petId}/name:
+    get:
+      operationId: getPetName
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+`
+	specPath := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(specPath, []byte(spec), 0644); err != nil {
+		t.Fatalf("Failed to write spec: %v", err)
+	}
+
+	srv, err := NewMCPServer(specPath)
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	tests := []struct {
+		requested      string
+		negotiated     string
+		hasTitle       bool
+		hasAnnotations bool
+		hasStructured  bool
+		hasCompletions bool
+	}{
+		{requested: "2025-06-18", negotiated: "2025-06-18", hasTitle: true, hasAnnotations: true, hasStructured: true, hasCompletions: true},
+		{requested: "2025-03-26", negotiated: "2025-03-26", hasTitle: false, hasAnnotations: true, hasStructured: false, hasCompletions: true},
+		{requested: "2024-11-05", negotiated: "2024-11-05", hasTitle: false, hasAnnotations: false, hasStructured: false, hasCompletions: false},
+		{requested: "2099-01-01", negotiated: "2025-06-18", hasTitle: true, hasAnnotations: true, hasStructured: true, hasCompletions: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.requested, func(t *testing.T) {
+			sess := newSession()
+			call := func(method, params string) *generator.MCPResponse {
+				request := generator.MCPRequest{
+					Jsonrpc: "2.0",
+					Method:  method,
+					Params:  json.RawMessage(params),
+					ID:      1,
+				}
+				response := srv.handleMessage(context.Background(), sess, &request)
+				if response == nil || response.Error != nil {
+					t.Fatalf("%s failed: %+v", method, response)
+				}
+				return response
+			}
+
+			var initResult generator.InitializeResult
+			response := call("initialize", `{"protocolVersion":"`+tt.requested+`"}`)
+			if err := json.Unmarshal(response.Result, &initResult); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if initResult.ProtocolVersion != tt.negotiated {
+				t.Errorf("Expected protocol version %s, got: %s", tt.negotiated, initResult.ProtocolVersion)
+			}
+			if (initResult.Capabilities.Completions != nil) != tt.hasCompletions {
+				t.Errorf("Expected completions capability present=%v, got: %v", tt.hasCompletions, initResult.Capabilities.Completions)
+			}
+
+			var listResult generator.ListToolsResult
+			response = call("tools/list", `{}`)
+			if err := json.Unmarshal(response.Result, &listResult); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			tool := listResult.Tools[0]
+			if (tool.Title != "") != tt.hasTitle {
+				t.Errorf("Expected title present=%v, got: %q", tt.hasTitle, tool.Title)
+			}
+			if (len(tool.OutputSchema) > 0) != tt.hasTitle {
+				t.Errorf("Expected outputSchema present=%v, got: %s", tt.hasTitle, tool.OutputSchema)
+			}
+			if (tool.Annotations != nil) != tt.hasAnnotations {
+				t.Errorf("Expected annotations present=%v, got: %+v", tt.hasAnnotations, tool.Annotations)
+			}
+
+			var callResult generator.CallToolResult
+			response = call("tools/call", `{"name":"getPet","arguments":{"petId":1}}`)
+			if err := json.Unmarshal(response.Result, &callResult); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if (callResult.StructuredContent != nil) != tt.hasStructured {
+				t.Errorf("Expected structuredContent present=%v, got: %v", tt.hasStructured, callResult.StructuredContent)
+			}
+			if callResult.Content[0].Text != `{"id": 1, "name": "Rex"}` {
+				t.Errorf("Expected response body as text, got: %s", callResult.Content[0].Text)
+			}
+
+			// A body that does not match the output schema is only an error
+			// for clients that were sent the schema.
+			callResult = generator.CallToolResult{}
+			response = call("tools/call", `{"name":"getPetName","arguments":{"petId":1}}`)
+			if err := json.Unmarshal(response.Result, &callResult); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if callResult.IsError != tt.hasStructured {
+				t.Errorf("Expected isError=%v for a non-object body, got: %+v", tt.hasStructured, callResult)
+			}
+		})
+	}
+}
+
+func TestProtocolVersionHeader(t *testing.T) {
+	srv, err := NewMCPServer("../examples/petstore.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	ts := httptest.NewServer(srv.HTTPHandler())
+	defer ts.Close()
+
+	resp := postMCP(t, ts.URL, "", "application/json", `{"jsonrpc":"2.0","method":"initialize","params":{"protocolVersion":"2025-06-18"},"id":1}`)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(sessionHeader)
+
+	for version, status := range map[string]int{"2025-06-18": http.StatusOK, "1999-01-01": http.StatusBadRequest} {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`{"jsonrpc":"2.0","method":"ping","id":2}`))
+		req.Header.Set(sessionHeader, sessionID)
+		req.Header.Set(versionHeader, version)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("Version %s: expected %d, got: %d", version, status, resp.StatusCode)
+		}
+	}
+}